@@ -0,0 +1,132 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 14:21:05
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// parseAt 解析 -at 参数：支持 RFC3339 时间或 Unix 秒数，为空时返回当前时间
+func parseAt(s string) (time.Time, error) {
+	if s == "" {
+		return time.Now(), nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的时间: %q（应为 RFC3339 或 Unix 秒数）", s)
+	}
+	return t, nil
+}
+
+// stepRange 返回 t 所在时间步的起止时间
+func stepRange(t time.Time, period int64) (start, end time.Time) {
+	start = time.Unix(t.Unix()/period*period, 0)
+	return start, start.Add(time.Duration(period) * time.Second)
+}
+
+// adhocCode 按给定参数计算指定时间的验证码，不读取也不写入账户文件
+// 按 RFC 6238 以 时间/步长 作为计数器，因此支持任意位数
+func adhocCode(cfg OTPConfig, t time.Time) (string, error) {
+	if cfg.Period <= 0 {
+		return "", fmt.Errorf("步长必须大于 0")
+	}
+	if t.Unix() < 0 {
+		return "", fmt.Errorf("时间不能早于 1970-01-01")
+	}
+	return totp.GenerateHOTP(cfg.Secret, uint64(t.Unix()/cfg.Period), cfg.Digits, cfg.Algorithm)
+}
+
+// secretEnv 未通过参数指定密钥时读取的环境变量
+const secretEnv = "TOTP_SECRET"
+
+// resolveSecret 按 -secret、-secret-file、环境变量 TOTP_SECRET 的顺序取得密钥
+// 推荐使用文件或环境变量，避免密钥出现在进程参数中被其他用户看到
+func resolveSecret(secret, secretFile string) (string, error) {
+	if secret != "" && secretFile != "" {
+		return "", fmt.Errorf("-secret 与 -secret-file 不能同时使用")
+	}
+	if secretFile != "" {
+		data, err := os.ReadFile(secretFile)
+		if err != nil {
+			return "", fmt.Errorf("读取密钥文件失败: %v", err)
+		}
+		secret = string(data)
+	}
+	if secret == "" {
+		secret = os.Getenv(secretEnv)
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", fmt.Errorf("请通过 -secret、-secret-file 或环境变量 %s 提供密钥", secretEnv)
+	}
+	return secret, nil
+}
+
+// readCodeLine 读取输入的第一行作为验证码
+func readCodeLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	code := strings.TrimSpace(line)
+	if code == "" {
+		return "", fmt.Errorf("未从标准输入读到验证码")
+	}
+	return code, nil
+}
+
+// readSecretLine 读取输入的第一行作为密钥
+func readSecretLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("读取密钥失败: %v", err)
+	}
+	secret := strings.TrimSpace(line)
+	if secret == "" {
+		return "", fmt.Errorf("未从标准输入读到密钥")
+	}
+	return secret, nil
+}
+
+// adhocVerify 在 t 前后 window 个时间步内验证验证码，不读取也不写入账户文件
+func adhocVerify(cfg OTPConfig, code string, t time.Time, window int) (bool, error) {
+	step := time.Duration(cfg.Period) * time.Second
+	for i := -window; i <= window; i++ {
+		at := t.Add(time.Duration(i) * step)
+		if at.Unix() < 0 {
+			continue
+		}
+		expected, err := adhocCode(cfg, at)
+		if err != nil {
+			return false, err
+		}
+		if totp.CodesEqual(expected, code) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// printProbe 输出验证码计算的每一步中间值（不输出密钥）
+func printProbe(raw totp.Raw, t time.Time, period int64) {
+	fmt.Fprintf(stdout, "时间:         %s (Unix %d)\n", t.UTC().Format(time.RFC3339), t.Unix())
+	fmt.Fprintf(stdout, "计数器:       %d = %d / %d\n", raw.Counter, t.Unix(), period)
+	fmt.Fprintf(stdout, "计数器字节:   %s\n", hex.EncodeToString(raw.CounterBytes[:]))
+	fmt.Fprintf(stdout, "HMAC-%s:%s%s\n", raw.Algorithm, strings.Repeat(" ", max(1, 8-len(raw.Algorithm))), hex.EncodeToString(raw.HMAC))
+	fmt.Fprintf(stdout, "截取偏移:     %d (摘要末字节 0x%02x & 0x0f)\n", raw.Offset, raw.HMAC[len(raw.HMAC)-1])
+	fmt.Fprintf(stdout, "31 位整数:    %d (0x%08x)\n", raw.BinCode, raw.BinCode)
+	fmt.Fprintf(stdout, "验证码:       %s\n", raw.Code)
+}
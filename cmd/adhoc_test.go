@@ -0,0 +1,76 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 06:55:31
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// rfcSecret RFC 6238 附录 B 中 SHA1 使用的种子 "12345678901234567890" 的 Base32 编码
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// withStdin 在测试期间以 content 作为标准输入
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = old
+		f.Close()
+	})
+}
+
+func TestVerifySecretAt(t *testing.T) {
+	testHome(t)
+	tests := []struct {
+		at   string
+		code string
+		ok   bool
+	}{
+		{"59", "94287082", true},
+		{"1970-01-01T00:00:59Z", "94287082", true},
+		{"1111111109", "07081804", true},
+		{"1111111109", "94287082", false}, // 其他时间的验证码
+		{"", "94287082", false},           // 不指定 -at 时使用本机当前时间
+	}
+	for _, tt := range tests {
+		withStdin(t, tt.code+"\n")
+		args := []string{"verify-secret", "-secret", rfcSecret, "-digits", "8", "-window", "0"}
+		if tt.at != "" {
+			args = append(args, "-at", tt.at)
+		}
+		_, err := runCLI(t, args...)
+		if tt.ok && err != nil {
+			t.Errorf("-at %q 验证 %s 应通过: %v", tt.at, tt.code, err)
+		}
+		if !tt.ok && exitCodeOf(err) != 1 {
+			t.Errorf("-at %q 验证 %s 应以退出码 1 失败: %v", tt.at, tt.code, err)
+		}
+	}
+
+	if _, err := runCLI(t, "verify-secret", "-secret", rfcSecret, "-at", "yesterday"); err == nil {
+		t.Error("无效的 -at 应返回错误")
+	}
+}
+
+func TestVerifyAccountAt(t *testing.T) {
+	testHome(t)
+	mustRun(t, "add", "-label", "rfc", "-secret", rfcSecret, "-digits", "8")
+	if _, err := runCLI(t, "verify", "-account", "rfc", "-window", "0", "-at", "1234567890", "89005924"); err != nil {
+		t.Errorf("以可信时间验证应通过: %v", err)
+	}
+	if _, err := runCLI(t, "verify", "-account", "rfc", "-window", "0", "89005924"); exitCodeOf(err) != 1 {
+		t.Errorf("以本机当前时间验证应失败: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 14:48:33
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// auditIssue 账户偏离 RFC 6238 常见默认配置的一项
+type auditIssue struct {
+	Field   string `json:"field"` // digits / period / algorithm / secret
+	Value   string `json:"value"` // 当前值（密钥只给出诊断结果，不输出内容）
+	Message string `json:"message"`
+}
+
+// auditResult 单个账户的检查结果
+type auditResult struct {
+	Version schemaVersion `json:"version"`
+	Label   string        `json:"label"`
+	Issues  []auditIssue  `json:"issues"`
+}
+
+// auditAccount 检查账户是否使用常见默认配置：6 位、30 秒、SHA1，以及密钥强度（见 totp.SecretStrength）
+// 只支持默认配置的验证器 App 可能无法正确使用偏离默认配置的账户
+func auditAccount(cfg OTPConfig) auditResult {
+	r := auditResult{Label: cfg.Label, Issues: []auditIssue{}}
+	if cfg.Digits != 0 && cfg.Digits != 6 {
+		r.Issues = append(r.Issues, auditIssue{"digits", strconv.Itoa(cfg.Digits), "位数不是 6"})
+	}
+	if cfg.Period != 0 && cfg.Period != totp.DefaultStep {
+		r.Issues = append(r.Issues, auditIssue{"period", strconv.FormatInt(cfg.Period, 10), "步长不是 30 秒"})
+	}
+	if cfg.Algorithm != "" && cfg.Algorithm != totp.SHA1 {
+		r.Issues = append(r.Issues, auditIssue{"algorithm", string(cfg.Algorithm), "算法不是 SHA1"})
+	}
+	// 密钥强度：全为零、过短或明显的低熵模式
+	bits, warnings := totp.SecretStrength(cfg.Secret)
+	if bits < 0 {
+		r.Issues = append(r.Issues, auditIssue{"secret", "invalid", "密钥无法解码"})
+		return r
+	}
+	for _, w := range warnings {
+		r.Issues = append(r.Issues, auditIssue{"secret", "weak", w})
+	}
+	return r
+}
+
+// accountHealth list 中账户的快速健康状态
+type accountHealth int
+
+const (
+	healthOK          accountHealth = iota // 密钥可解码且使用常见默认配置
+	healthNonstd                           // 参数偏离常见默认配置或密钥强度不足
+	healthUndecodable                      // 密钥无法解码
+)
+
+// checkHealth 复用 auditAccount 的检查对账户分级（不检查密钥复用）
+func checkHealth(cfg OTPConfig) accountHealth {
+	r := auditAccount(cfg)
+	for _, issue := range r.Issues {
+		if issue.Field == "secret" && issue.Value == "invalid" {
+			return healthUndecodable
+		}
+	}
+	if len(r.Issues) > 0 {
+		return healthNonstd
+	}
+	return healthOK
+}
+
+// color 状态对应的颜色（禁用颜色时为空）
+func (h accountHealth) color() string {
+	switch h {
+	case healthNonstd:
+		return Yellow
+	case healthUndecodable:
+		return Red
+	}
+	return Green
+}
+
+// auditAccounts 检查所有账户，只返回存在偏离项的账户
+// 多个账户使用相同密钥（规范化后相同）时一并提示，通常是重复导入或复制账户时忘了改密钥
+func auditAccounts(accounts []OTPConfig) []auditResult {
+	bySecret := make(map[string][]string, len(accounts))
+	for _, cfg := range accounts {
+		key := totp.NormalizeSecret(cfg.Secret)
+		bySecret[key] = append(bySecret[key], cfg.Label)
+	}
+	results := []auditResult{}
+	for _, cfg := range accounts {
+		r := auditAccount(cfg)
+		var others []string
+		for _, label := range bySecret[totp.NormalizeSecret(cfg.Secret)] {
+			if label != cfg.Label {
+				others = append(others, label)
+			}
+		}
+		if len(others) > 0 {
+			r.Issues = append(r.Issues, auditIssue{"secret", "reused", "与 " + strings.Join(others, ", ") + " 使用相同的密钥"})
+		}
+		if len(r.Issues) > 0 {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// printAudit 输出检查结果
+func printAudit(accounts []OTPConfig, asJSON bool) error {
+	results := auditAccounts(accounts)
+	if asJSON {
+		return printJSON(results)
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(stdout, "%s✅ 全部 %d 个账户均使用常见默认配置（6 位 / 30 秒 / SHA1）%s\n", Green, len(accounts), Reset)
+		return nil
+	}
+	for _, r := range results {
+		fmt.Fprintf(stdout, "%s⚠️ %s%s\n", Yellow, r.Label, Reset)
+		for _, issue := range r.Issues {
+			fmt.Fprintf(stdout, "   - %s: %s (%s)\n", issue.Field, issue.Message, issue.Value)
+		}
+	}
+	fmt.Fprintf(stdout, "共 %d 个账户，其中 %d 个偏离常见默认配置，部分验证器 App 可能不支持\n", len(accounts), len(results))
+	return nil
+}
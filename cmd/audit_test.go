@@ -0,0 +1,55 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 07:58:14
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  OTPConfig
+		want accountHealth
+	}{
+		{"默认配置", OTPConfig{Secret: rfcSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6}, healthOK},
+		{"早期账户的零值参数", OTPConfig{Secret: rfcSecret}, healthOK},
+		{"格式不同的密钥", OTPConfig{Secret: strings.ToLower(rfcSecret[:8]) + " " + rfcSecret[8:]}, healthOK},
+		{"8 位", OTPConfig{Secret: rfcSecret, Digits: 8}, healthNonstd},
+		{"60 秒步长", OTPConfig{Secret: rfcSecret, Period: 60}, healthNonstd},
+		{"SHA256", OTPConfig{Secret: rfcSecret, Algorithm: totp.SHA256}, healthNonstd},
+		{"80 位密钥", OTPConfig{Secret: testSecret}, healthNonstd},
+		{"全零密钥", OTPConfig{Secret: strings.Repeat("A", 32)}, healthNonstd},
+		{"无法解码", OTPConfig{Secret: "NOT-BASE32!"}, healthUndecodable},
+		{"无法解码且参数非标准", OTPConfig{Secret: "NOT-BASE32!", Digits: 8}, healthUndecodable},
+	}
+	for _, tt := range tests {
+		if got := checkHealth(tt.cfg); got != tt.want {
+			t.Errorf("%s: checkHealth = %d，期望 %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListHealthColor(t *testing.T) {
+	testHome(t)
+	path := newTestStore(t,
+		OTPConfig{Label: "good", Secret: rfcSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6},
+		OTPConfig{Label: "odd", Secret: rfcSecret, Algorithm: totp.SHA1, Period: 60, Digits: 6},
+		OTPConfig{Label: "broken", Secret: "NOT-BASE32!", Algorithm: totp.SHA1, Period: 30, Digits: 6},
+	)
+	out := mustRun(t, "-force-color", "-file", path, "list")
+	for label, color := range map[string]string{"good": ansiColors[2], "odd": ansiColors[3], "broken": ansiColors[1]} {
+		if !strings.Contains(out, color+label+ansiColors[0]) {
+			t.Errorf("%s 的颜色不正确:\n%q", label, out)
+		}
+	}
+
+	// -no-color 时不输出转义序列
+	if out := mustRun(t, "-no-color", "-file", path, "list"); strings.Contains(out, "\x1b[") {
+		t.Errorf("-no-color 时不应输出颜色:\n%q", out)
+	}
+}
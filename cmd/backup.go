@@ -0,0 +1,215 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 01:52:37
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxBackups 最多保留的历史版本数
+const maxBackups = 99
+
+// backupFlag 全局参数 -backups 指定的保留数，<0 表示未指定（使用本机配置中的 backups）
+var backupFlag = -1
+
+// backupRetention 当前生效的历史版本保留数，0 表示不保留（默认）
+func backupRetention() int {
+	if backupFlag >= 0 {
+		return backupFlag
+	}
+	return localPrefs.Backups
+}
+
+// parseBackups 解析 -backups 参数
+func parseBackups(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > maxBackups {
+		return 0, fmt.Errorf("无效的备份数: %q（应为 0~%d）", s, maxBackups)
+	}
+	return n, nil
+}
+
+// backupPath 第 n 个历史版本的路径：<账户文件>.1 为最近一次保存前的版本
+func backupPath(accountFile string, n int) string {
+	return accountFile + "." + strconv.Itoa(n)
+}
+
+// backupFile 已存在的一个历史版本
+type backupFile struct {
+	n    int
+	path string
+}
+
+// listBackups 返回已存在的历史版本，按序号从新到旧排列（序号可能不连续，例如调小保留数之后）
+func listBackups(accountFile string) ([]backupFile, error) {
+	entries, err := os.ReadDir(filepath.Dir(accountFile))
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(accountFile) + "."
+	var backups []backupFile
+	for _, e := range entries {
+		suffix, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		// 只接受不带前导零的正整数，避免把 .hmac、.lock 等文件当成备份
+		if n, err := strconv.Atoi(suffix); err == nil && n > 0 && strconv.Itoa(n) == suffix {
+			backups = append(backups, backupFile{n, backupPath(accountFile, n)})
+		}
+	}
+	slices.SortFunc(backups, func(a, b backupFile) int { return cmp.Compare(a.n, b.n) })
+	return backups, nil
+}
+
+// rotateBackups 将现有账户文件保存为 .1，原有的 .1 ~ .(keep-1) 依次后移，超出 keep 的版本删除
+// 在新内容重命名到位之前调用：任何时刻账户文件本身都是完整的
+func rotateBackups(accountFile string, keep int) error {
+	if _, err := os.Stat(accountFile); os.IsNotExist(err) {
+		return nil
+	}
+	backups, err := listBackups(accountFile)
+	if err != nil {
+		return err
+	}
+	for _, b := range slices.Backward(backups) {
+		if b.n >= keep {
+			if err := os.Remove(b.path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Rename(b.path, backupPath(accountFile, b.n+1)); err != nil {
+			return err
+		}
+	}
+	// 硬链接不复制数据，随后的重命名会让账户文件指向新内容；不支持硬链接时退回复制
+	// 历史版本同样包含密钥，旧版本创建的 0644 文件链接后收紧为 0600
+	if err := os.Link(accountFile, backupPath(accountFile, 1)); err != nil {
+		return copyFile(accountFile, backupPath(accountFile, 1))
+	}
+	return os.Chmod(backupPath(accountFile, 1), 0600)
+}
+
+// storeChanged 报告 accounts 与账户文件现有内容相比是否有实质变化
+// 与完整性校验相同，比较时忽略最近使用时间和 HOTP 计数器（见 canonicalAccounts）；文件无法读取或解析时视为有变化
+func storeChanged(accountFile string, accounts []OTPConfig) bool {
+	current, err := readAccountFile(accountFile)
+	if err != nil {
+		return true
+	}
+	before, err := canonicalAccounts(current)
+	if err != nil {
+		return true
+	}
+	after, err := canonicalAccounts(accounts)
+	return err != nil || !bytes.Equal(before, after)
+}
+
+// copyFile 复制文件内容，目标文件权限为 0600
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// realPath 解析符号链接，使原子写入替换链接指向的文件而不是链接本身；文件不存在时原样返回
+func realPath(path string) string {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		return p
+	}
+	return path
+}
+
+// writeFileAtomic 先写入同目录下的临时文件并落盘，再重命名覆盖 path，中途失败不会留下写了一半的文件
+// path 已存在时沿用其权限但不宽于 perm（旧版本以 0644 创建的账户文件在此收紧），否则使用 perm；
+// before 不为 nil 时在重命名前调用（用于轮换历史版本）
+func writeFileAtomic(path string, data []byte, perm os.FileMode, before func() error) error {
+	if info, err := os.Stat(path); err == nil {
+		perm &= info.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // 重命名成功后删除不存在的文件，忽略错误
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	if before != nil {
+		if err := before(); err != nil {
+			return fmt.Errorf("轮换历史版本失败: %w", err)
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+// rollbackStore 用最近的历史版本覆盖账户文件，该版本随之移除，其余版本序号依次前移
+// 被覆盖的当前内容不再保留；恢复前会检查历史版本能否正常解析
+func rollbackStore(accountFile string) error {
+	accountFile = realPath(accountFile)
+	backups, err := listBackups(accountFile)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return fmt.Errorf("没有可恢复的历史版本（使用全局参数 -backups 或本机配置 backups 开启）")
+	}
+	latest := backups[0]
+	data, err := os.ReadFile(latest.path)
+	if err != nil {
+		return err
+	}
+	accounts, err := decodeStore(data)
+	if err != nil {
+		return fmt.Errorf("历史版本 %s 无法解析: %v", latest.path, err)
+	}
+	if err := writeFileAtomic(accountFile, data, 0600, nil); err != nil {
+		return err
+	}
+	if err := os.Remove(latest.path); err != nil {
+		return err
+	}
+	for i, b := range backups[1:] {
+		if err := os.Rename(b.path, backupPath(accountFile, i+1)); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "✅ 已从 %s 恢复 %d 个账户，剩余 %d 个历史版本\n", latest.path, len(accounts), len(backups)-1)
+	if _, err := os.Stat(sealPath(accountFile)); err == nil {
+		fmt.Fprintf(stdout, "%s⚠️ 账户文件已改变，请重新运行 seal-store 更新完整性校验信息%s\n", Yellow, Reset)
+	}
+	return nil
+}
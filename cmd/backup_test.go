@@ -0,0 +1,160 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 08:31:26
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// storeLabels 返回账户文件中的账户名
+func storeLabels(t *testing.T, path string) []string {
+	t.Helper()
+	accounts, err := readAccountFile(path)
+	if err != nil {
+		t.Fatalf("读取 %s: %v", path, err)
+	}
+	labels := []string{}
+	for _, a := range accounts {
+		labels = append(labels, a.Label)
+	}
+	return labels
+}
+
+// checkPerm 检查文件权限（Windows 不支持 Unix 权限位，跳过）
+func checkPerm(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != want {
+		t.Errorf("%s 的权限应为 %o: %v %v", path, want, info.Mode(), err)
+	}
+}
+
+func TestStoreFilePermissions(t *testing.T) {
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	mustRun(t, "-file", path, "list")
+	checkPerm(t, path, 0600)
+
+	mustRun(t, "-file", path, "add", "-label", "alice", "-secret", testSecret)
+	checkPerm(t, path, 0600)
+
+	// 旧版本以 0644 创建的账户文件在下次保存时收紧
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, "-file", path, "-backups", "1", "add", "-label", "bob", "-secret", testSecret)
+	checkPerm(t, path, 0600)
+	checkPerm(t, backupPath(path, 1), 0600)
+
+	// 已比 0600 更严格的权限保持不变
+	if err := os.Chmod(path, 0400); err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, "-file", path, "add", "-label", "carol", "-secret", testSecret)
+	checkPerm(t, path, 0400)
+}
+
+func TestBackupRotation(t *testing.T) {
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	for _, label := range []string{"a", "b", "c", "d"} {
+		mustRun(t, "-file", path, "-backups", "2", "add", "-label", label, "-secret", testSecret)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path, []string{"a", "b", "c", "d"}},
+		{backupPath(path, 1), []string{"a", "b", "c"}},
+		{backupPath(path, 2), []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		if got := storeLabels(t, tt.path); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v，期望 %v", filepath.Base(tt.path), got, tt.want)
+		}
+		checkPerm(t, tt.path, 0600)
+	}
+	if _, err := os.Stat(backupPath(path, 3)); !os.IsNotExist(err) {
+		t.Errorf("超出保留数的历史版本应删除: %v", err)
+	}
+
+	// 未开启 -backups 时保存不轮换
+	mustRun(t, "-file", path, "add", "-label", "e", "-secret", testSecret)
+	if got := storeLabels(t, backupPath(path, 1)); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("未开启 -backups 时不应轮换: .1 = %v", got)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	for _, label := range []string{"a", "b", "c"} {
+		mustRun(t, "-file", path, "-backups", "3", "add", "-label", label, "-secret", testSecret)
+	}
+
+	steps := [][]string{{"a", "b"}, {"a"}, {}}
+	for i, want := range steps {
+		mustRun(t, "-file", path, "rollback")
+		if got := storeLabels(t, path); !slices.Equal(got, want) {
+			t.Errorf("第 %d 次恢复后为 %v，期望 %v", i+1, got, want)
+		}
+		checkPerm(t, path, 0600)
+		backups, err := listBackups(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if left := len(steps) - 1 - i; len(backups) != left {
+			t.Errorf("第 %d 次恢复后剩余 %d 个历史版本，期望 %d", i+1, len(backups), left)
+		}
+	}
+	if _, err := runCLI(t, "-file", path, "rollback"); err == nil {
+		t.Error("没有历史版本时 rollback 应报错")
+	}
+
+	// 无法解析的历史版本不会覆盖账户文件
+	mustRun(t, "-file", path, "add", "-label", "x", "-secret", testSecret)
+	if err := os.WriteFile(backupPath(path, 1), []byte("{broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCLI(t, "-file", path, "rollback"); err == nil {
+		t.Error("无法解析的历史版本应拒绝恢复")
+	}
+	if got := storeLabels(t, path); !slices.Equal(got, []string{"x"}) {
+		t.Errorf("恢复失败时账户文件不应改变: %v", got)
+	}
+}
+
+func TestBackupSkipsUsageWrites(t *testing.T) {
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	mustRun(t, "-file", path, "-backups", "2", "add", "-label", "alice", "-secret", testSecret)
+	mustRun(t, "-file", path, "-backups", "2", "edit", "-set-digits", "8", "alice")
+	// 只更新使用时间的保存不应挤掉修改前的历史版本
+	for range 2 {
+		mustRun(t, "-file", path, "-backups", "2", "gen", "-account", "alice")
+	}
+	accounts, err := readAccountFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].LastUsedAt.IsZero() {
+		t.Fatalf("gen 应记录使用时间: %+v", accounts)
+	}
+
+	mustRun(t, "-file", path, "rollback")
+	accounts, err = readAccountFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].Digits != 6 {
+		t.Errorf("rollback 应撤销 edit，恢复为 6 位: %+v", accounts)
+	}
+}
@@ -0,0 +1,109 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 17:58:12
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// bigFont 大号数字字模，每个数字 5 行 x 5 列
+var bigFont = [10][5]string{
+	{" ███ ", "█   █", "█   █", "█   █", " ███ "},
+	{"  █  ", " ██  ", "  █  ", "  █  ", " ███ "},
+	{" ███ ", "█   █", "  ██ ", " █   ", "█████"},
+	{"████ ", "    █", " ███ ", "    █", "████ "},
+	{"█   █", "█   █", "█████", "    █", "    █"},
+	{"█████", "█    ", "████ ", "    █", "████ "},
+	{" ███ ", "█    ", "████ ", "█   █", " ███ "},
+	{"█████", "    █", "   █ ", "  █  ", "  █  "},
+	{" ███ ", "█   █", " ███ ", "█   █", " ███ "},
+	{" ███ ", "█   █", " ████", "    █", " ███ "},
+}
+
+// 大字显示的布局参数
+const (
+	bigFontHeight = 5
+	bigFontWidth  = 5
+	bigDigitGap   = 2                              // 数字之间的空白
+	bigCodeRow    = headerLines + 3                // 大号验证码的起始行（标题、账户名之后空一行）
+	bigTimerRow   = bigCodeRow + bigFontHeight + 1 // 剩余时间所在行
+	bigFooterRow  = bigTimerRow + 2
+)
+
+// bigLines 将验证码渲染为大号数字（分组显示时的空格渲染为额外空白）
+func bigLines(code string) []string {
+	lines := make([]string, bigFontHeight)
+	gap := strings.Repeat(" ", bigDigitGap)
+	for i, r := range code {
+		for row := range lines {
+			if i > 0 {
+				lines[row] += gap
+			}
+			if r >= '0' && r <= '9' {
+				lines[row] += bigFont[r-'0'][row]
+			} else {
+				lines[row] += strings.Repeat(" ", bigDigitGap)
+			}
+		}
+	}
+	return lines
+}
+
+// bigWidth 返回 digits 位验证码大字显示所需的宽度（含一组分隔）
+func bigWidth(digits int) int {
+	return digits*(bigFontWidth+bigDigitGap) + bigDigitGap
+}
+
+// bigFits 判断终端是否足够大字显示该账户；终端大小未知时按足够处理
+func bigFits(cfg OTPConfig, rows, cols int) bool {
+	digits := cfg.Digits
+	if digits <= 0 {
+		digits = 6
+	}
+	if cols > 0 && cols < bigWidth(digits) {
+		return false
+	}
+	return rows <= 0 || rows >= bigFooterRow
+}
+
+// displayBig 以大号数字动态显示单个账户的验证码
+func displayBig(cfg OTPConfig, opts displayOptions, codes *stepCache, firstDraw bool) {
+	if firstDraw {
+		term.ClearScreen()
+		fmt.Fprintln(stdout, Bold+Cyan+"🔐 多账户动态 TOTP 管理器"+Reset)
+		fmt.Fprintln(stdout, strings.Repeat("=", blockWidth))
+		if cfg.Issuer != "" {
+			fmt.Fprintf(stdout, "%s (%s)\n", cfg.Name(), cfg.Issuer)
+		} else {
+			fmt.Fprintln(stdout, cfg.Name())
+		}
+		term.MoveTo(bigFooterRow, 1)
+		if opts.refreshKey {
+			fmt.Fprintln(stdout, "按 r 立即刷新 | 按 Ctrl+C 退出")
+		} else {
+			fmt.Fprintln(stdout, "按 Ctrl+C 退出")
+		}
+		return
+	}
+
+	term.MoveTo(bigCodeRow, 1)
+	now := time.Now()
+	code, left, period, err := codes.at(cfg, now)
+	if err != nil {
+		fmt.Fprintf(stdout, "%s❌ 生成失败: %v%s", Red, err, Reset)
+		return
+	}
+	if float64(left) <= opts.beep.seconds(period) {
+		beepWithCooldown(now)
+	}
+	for i, line := range bigLines(opts.formatCode(code)) {
+		term.MoveTo(bigCodeRow+i, 1)
+		fmt.Fprintf(stdout, "%s%s%s   ", Green, line, Reset)
+	}
+	term.MoveTo(bigTimerRow, 1)
+	fmt.Fprintf(stdout, "剩余时间: %2d 秒 [%s]", left, progressBar(float64(period), float64(left), opts.warn.seconds(period)))
+	term.MoveTo(bigFooterRow+1, 1)
+}
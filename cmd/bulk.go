@@ -0,0 +1,104 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 21:42:30
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// bulkColumns CSV 的列顺序，label 与 secret 必填，其余留空时使用默认值
+var bulkColumns = []string{"label", "secret", "algo", "digits", "period"}
+
+// parseBulkRow 将一行 CSV 解析为账户参数
+func parseBulkRow(record []string) (OTPConfig, error) {
+	if len(record) < 2 || len(record) > len(bulkColumns) {
+		return OTPConfig{}, fmt.Errorf("应为 %d~%d 列（%s），实际 %d 列", 2, len(bulkColumns), strings.Join(bulkColumns, ","), len(record))
+	}
+	get := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	cfg := OTPConfig{
+		Label:     get(0),
+		Secret:    get(1),
+		Algorithm: totp.SHA1,
+		Digits:    6,
+		Period:    totp.DefaultStep,
+	}
+	if cfg.Label == "" || cfg.Secret == "" {
+		return OTPConfig{}, fmt.Errorf("label 与 secret 不能为空")
+	}
+	if v := get(2); v != "" {
+		cfg.Algorithm = totp.Algorithm(strings.ToUpper(v))
+		if err := checkAlgorithm(cfg.Algorithm); err != nil {
+			return OTPConfig{}, err
+		}
+	}
+	if v := get(3); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return OTPConfig{}, fmt.Errorf("无效的位数: %q", v)
+		}
+		cfg.Digits = n
+	}
+	if v := get(4); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return OTPConfig{}, fmt.Errorf("无效的步长: %q", v)
+		}
+		cfg.Period = n
+	}
+	return cfg, nil
+}
+
+// bulkCodes 逐行输出 CSV 中每个密钥在 t 时刻的验证码，不读取也不写入账户文件
+// 单行出错时输出错误并继续处理后续行，全部处理完后返回失败的行数
+func bulkCodes(r io.Reader, t time.Time) (failed int, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // 列数由 parseBulkRow 检查，便于按行报告
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return failed, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			fmt.Fprintf(stdout, "%s❌ 第 %d 行: %v%s\n", Red, parseErr.Line, parseErr.Err, Reset)
+			failed++
+			continue
+		}
+		if err != nil {
+			return failed, err
+		}
+		line, _ := reader.FieldPos(0)
+		// 第一行为表头时跳过
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "label") {
+			continue
+		}
+		cfg, err := parseBulkRow(record)
+		if err == nil {
+			var code string
+			if code, err = adhocCode(cfg, t); err == nil {
+				_, end := stepRange(t, cfg.Period)
+				fmt.Fprintf(stdout, "%s: %s%s%s (剩余 %d 秒)\n", cfg.Label, Green, code, Reset, int(end.Sub(t).Seconds()))
+				continue
+			}
+		}
+		fmt.Fprintf(stdout, "%s❌ 第 %d 行 (%s): %v%s\n", Red, line, strings.TrimSpace(record[0]), err, Reset)
+		failed++
+	}
+}
@@ -0,0 +1,168 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 00:31:12
+package cmd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// bundlePassphraseEnv 加密注册包口令的环境变量，未设置时从标准输入读取
+const bundlePassphraseEnv = "TOTP_BUNDLE_PASSPHRASE"
+
+// bundleVersion 注册包文件的格式版本，与 -json 输出的 jsonSchemaVersion 相互独立
+// 注册包会被其他工具长期读取，修改字段或加密方式时递增
+const bundleVersion = 1
+
+// bundleEntry 注册包中的一个账户，交给对应用户录入验证器 App
+type bundleEntry struct {
+	Label  string `json:"label"`
+	Issuer string `json:"issuer,omitempty"`
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// URI，可直接生成二维码供用户扫描
+}
+
+// bundle 离线注册包：批量为用户生成的新账户
+type bundle struct {
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"created_at"`
+	Accounts  []bundleEntry `json:"accounts"`
+}
+
+// sealedBundle 加密后的注册包：AES-256-GCM，密钥由口令和盐经 PBKDF2-HMAC-SHA256 派生
+type sealedBundle struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`       // 十六进制
+	Nonce      string `json:"nonce"`      // 十六进制
+	Ciphertext string `json:"ciphertext"` // Base64，解密后为 bundle 的 JSON
+}
+
+// otpauthURI 生成账户的 otpauth:// URI（与 parseOtpauthURL 互逆），默认参数不写入以兼容更多 App
+// 显示名称与图标只用于本地展示，不写入 URI
+func otpauthURI(cfg OTPConfig) string {
+	q := url.Values{}
+	q.Set("secret", cfg.Secret)
+	if cfg.Issuer != "" {
+		q.Set("issuer", cfg.Issuer)
+	}
+	if cfg.Algorithm != "" && cfg.Algorithm != totp.SHA1 {
+		q.Set("algorithm", string(cfg.Algorithm))
+	}
+	if cfg.Digits != 0 && cfg.Digits != 6 {
+		q.Set("digits", strconv.Itoa(cfg.Digits))
+	}
+	if cfg.Period != 0 && cfg.Period != totp.DefaultStep {
+		q.Set("period", strconv.FormatInt(cfg.Period, 10))
+	}
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + cfg.Label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// newBundle 为每个 label 生成新密钥，组成注册包
+func newBundle(labels []string, issuer string) (bundle, error) {
+	b := bundle{Version: bundleVersion, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	for _, label := range labels {
+		secret, err := totp.GenerateSecret(0)
+		if err != nil {
+			return bundle{}, fmt.Errorf("生成密钥失败: %v", err)
+		}
+		cfg := OTPConfig{Label: label, Issuer: issuer, Secret: secret}
+		b.Accounts = append(b.Accounts, bundleEntry{Label: label, Issuer: issuer, Secret: secret, URI: otpauthURI(cfg)})
+	}
+	return b, nil
+}
+
+// sealBundle 用口令加密注册包的 JSON
+func sealBundle(plain []byte, passphrase string) (sealedBundle, error) {
+	salt := make([]byte, sealSaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return sealedBundle{}, err
+	}
+	key, err := totp.DeriveSecret([]byte(passphrase), salt, sha256.Size)
+	if err != nil {
+		return sealedBundle{}, err
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return sealedBundle{}, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return sealedBundle{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return sealedBundle{}, err
+	}
+	return sealedBundle{
+		Version:    bundleVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: totp.DeriveIterations,
+		Salt:       hex.EncodeToString(salt),
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, nil)),
+	}, nil
+}
+
+// marshalBundle 以缩进格式序列化，不转义 URI 中的 &，便于直接复制
+func marshalBundle(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBundle 将注册包写入 path（权限 0600），passphrase 非空时加密；force 为 false 时不覆盖已有文件
+func writeBundle(path string, b bundle, passphrase string, force bool) error {
+	data, err := marshalBundle(b)
+	if err != nil {
+		return err
+	}
+	defer clear(data)
+	if passphrase != "" {
+		sealed, err := sealBundle(data, passphrase)
+		if err != nil {
+			return fmt.Errorf("加密注册包失败: %v", err)
+		}
+		if data, err = marshalBundle(sealed); err != nil {
+			return err
+		}
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0600)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s 已存在（使用 -force 覆盖）", path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,165 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 07:45:33
+package cmd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// openBundle 按 sealedBundle 记录的参数用口令解密注册包
+func openBundle(t *testing.T, data []byte, passphrase string) bundle {
+	t.Helper()
+	var sealed sealedBundle
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		t.Fatal(err)
+	}
+	if sealed.Version != bundleVersion || sealed.KDF != "pbkdf2-sha256" || sealed.Iterations != totp.DeriveIterations {
+		t.Fatalf("加密注册包的参数不正确: %+v", sealed)
+	}
+	salt, err := hex.DecodeString(sealed.Salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := hex.DecodeString(sealed.Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totp.DeriveSecret([]byte(passphrase), salt, sha256.Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	var b bundle
+	if err := json.Unmarshal(plain, &b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// checkBundle 检查注册包的版本、账户顺序，以及每个 URI 与其中的密钥一致
+func checkBundle(t *testing.T, b bundle, issuer string, labels ...string) {
+	t.Helper()
+	if b.Version != bundleVersion {
+		t.Errorf("注册包版本为 %d，期望 %d", b.Version, bundleVersion)
+	}
+	if len(b.Accounts) != len(labels) {
+		t.Fatalf("注册包包含 %d 个账户，期望 %d", len(b.Accounts), len(labels))
+	}
+	secrets := make(map[string]bool)
+	for i, e := range b.Accounts {
+		if e.Label != labels[i] || e.Issuer != issuer {
+			t.Errorf("第 %d 个账户为 %s/%s，期望 %s/%s", i+1, e.Label, e.Issuer, labels[i], issuer)
+		}
+		if bits, warnings := totp.SecretStrength(e.Secret); bits < 160 || len(warnings) != 0 {
+			t.Errorf("%s: 生成的密钥强度不足: %d 位 %v", e.Label, bits, warnings)
+		}
+		if secrets[e.Secret] {
+			t.Errorf("%s: 密钥与其他账户重复", e.Label)
+		}
+		secrets[e.Secret] = true
+
+		cfg, err := parseOtpauthURL(e.URI)
+		if err != nil {
+			t.Fatalf("%s: 解析 URI 失败: %v", e.Label, err)
+		}
+		if cfg.Label != e.Label || cfg.Secret != e.Secret || cfg.Issuer != issuer {
+			t.Errorf("%s: URI %s 与注册包内容不一致", e.Label, e.URI)
+		}
+	}
+}
+
+func TestExportBundle(t *testing.T) {
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	mustRun(t, "export-bundle", "-issuer", "Example Corp", path, "alice", "bob@example.com")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatal(err)
+	}
+	checkBundle(t, b, "Example Corp", "alice", "bob@example.com")
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("注册包权限应为 0600: %v %v", info.Mode(), err)
+		}
+	}
+	if strings.Contains(mustRun(t, "list"), "alice") {
+		t.Error("导出注册包不应保存账户")
+	}
+}
+
+func TestExportBundleEncrypted(t *testing.T) {
+	testHome(t)
+	const passphrase = "correct horse battery staple"
+	t.Setenv(bundlePassphraseEnv, passphrase)
+	path := filepath.Join(t.TempDir(), "bundle.sealed")
+	mustRun(t, "export-bundle", "-encrypt", path, "alice", "bob", "carol")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("otpauth://")) || bytes.Contains(data, []byte("alice")) {
+		t.Fatal("加密后的注册包不应包含明文")
+	}
+	checkBundle(t, openBundle(t, data, passphrase), "", "alice", "bob", "carol")
+}
+
+func TestExportBundleNoOverwrite(t *testing.T) {
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, []byte("existing"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := runCLI(t, "export-bundle", path, "alice")
+	if err == nil || !strings.Contains(err.Error(), "-force") {
+		t.Errorf("文件已存在时应拒绝覆盖并提示 -force: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "existing" {
+		t.Errorf("未加 -force 时原文件不应被修改: %q", data)
+	}
+
+	mustRun(t, "export-bundle", "-force", path, "alice")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatalf("-force 后应写入新的注册包: %v", err)
+	}
+	checkBundle(t, b, "", "alice")
+}
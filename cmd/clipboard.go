@@ -0,0 +1,102 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 11:51:33
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// clipboardCommand 系统剪贴板命令（按平台依次尝试）
+type clipboardCommand struct {
+	name string
+	args []string
+}
+
+// pasteCommands 返回当前平台读取剪贴板的候选命令
+func pasteCommands() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{{"pbpaste", nil}}
+	case "windows":
+		return []clipboardCommand{{"powershell", []string{"-NoProfile", "-Command", "Get-Clipboard"}}}
+	default:
+		return []clipboardCommand{
+			{"wl-paste", []string{"--no-newline"}},
+			{"xclip", []string{"-selection", "clipboard", "-o"}},
+			{"xsel", []string{"--clipboard", "--output"}},
+		}
+	}
+}
+
+// readClipboard 读取系统剪贴板中的文本
+func readClipboard() (string, error) {
+	var tried []string
+	for _, c := range pasteCommands() {
+		if _, err := exec.LookPath(c.name); err != nil {
+			tried = append(tried, c.name)
+			continue
+		}
+		out, err := exec.Command(c.name, c.args...).Output()
+		if err != nil {
+			return "", fmt.Errorf("读取剪贴板失败 (%s): %w", c.name, err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", fmt.Errorf("未找到可用的剪贴板工具 (%s)", strings.Join(tried, "/"))
+}
+
+// copyCommands 返回当前平台写入剪贴板的候选命令（内容从标准输入读取）
+func copyCommands() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{{"pbcopy", nil}}
+	case "windows":
+		return []clipboardCommand{{"clip", nil}}
+	default:
+		return []clipboardCommand{
+			{"wl-copy", nil},
+			{"xclip", []string{"-selection", "clipboard", "-i"}},
+			{"xsel", []string{"--clipboard", "--input"}},
+		}
+	}
+}
+
+// writeClipboard 将文本写入系统剪贴板，text 为空时清空剪贴板
+func writeClipboard(text string) error {
+	var tried []string
+	for _, c := range copyCommands() {
+		if _, err := exec.LookPath(c.name); err != nil {
+			tried = append(tried, c.name)
+			continue
+		}
+		cmd := exec.Command(c.name, c.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("写入剪贴板失败 (%s): %w", c.name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("未找到可用的剪贴板工具 (%s)", strings.Join(tried, "/"))
+}
+
+// defaultClipClear 复制验证码后自动清除剪贴板的默认等待时间
+const defaultClipClear = 20 * time.Second
+
+// clearClipboardAfter 等待 d 后清空剪贴板，但只在剪贴板仍是 text 时清空，避免覆盖用户之后复制的内容
+// 返回是否实际清空
+func clearClipboardAfter(text string, d time.Duration) (bool, error) {
+	time.Sleep(d)
+	current, err := readClipboard()
+	if err != nil {
+		return false, err
+	}
+	if current != text {
+		return false, nil
+	}
+	return true, writeClipboard("")
+}
@@ -0,0 +1,53 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 17:31:46
+package cmd
+
+import (
+	"io"
+	"os"
+)
+
+// colorMode 颜色输出模式（由全局参数决定）
+type colorMode int
+
+const (
+	colorAuto   colorMode = iota // 按环境变量和终端检测决定
+	colorAlways                  // -force-color
+	colorNever                   // -no-color
+)
+
+// ansiColors 启用颜色时使用的 ANSI 颜色码
+var ansiColors = [...]string{"\033[0m", "\033[31m", "\033[32m", "\033[33m", "\033[36m", "\033[1m"}
+
+// useColor 判断是否输出颜色，优先级从高到低：
+//  1. -no-color
+//  2. -force-color
+//  3. 环境变量 NO_COLOR（非空即关闭）
+//  4. 环境变量 CLICOLOR_FORCE（非空且不为 0 即开启）
+//  5. 输出是否为终端（管道、重定向到文件时关闭）
+func useColor(mode colorMode, w io.Writer) bool {
+	switch mode {
+	case colorNever:
+		return false
+	case colorAlways:
+		return true
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return true
+	}
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// setColor 开启或关闭颜色输出
+func setColor(enabled bool) {
+	colors := ansiColors
+	if !enabled {
+		colors = [len(ansiColors)]string{}
+	}
+	Reset, Red, Green, Yellow, Cyan, Bold = colors[0], colors[1], colors[2], colors[3], colors[4], colors[5]
+}
@@ -0,0 +1,1133 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 12:36:14
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// command 子命令定义
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands 所有子命令（在 init 中赋值，避免 help 与命令表互相引用导致初始化循环）
+var commands []command
+
+func init() {
+	commands = []command{
+		{"add", "添加账户（otpauth:// URI 或手动输入）", cmdAdd},
+		{"remove", "删除账户", cmdRemove},
+		{"list", "列出所有账户", cmdList},
+		{"verify", "验证输入的验证码", cmdVerify},
+		{"watch", "动态显示验证码（默认行为）", cmdWatch},
+		{"gen", "输出一次当前验证码", cmdGen},
+		{"diff", "与另一个账户文件比较差异", cmdDiff},
+		{"hotp", "按计数器范围批量输出 HOTP 验证码", cmdHOTP},
+		{"rename", "修改账户的显示名称", cmdRename},
+		{"edit", "修改账户的算法、位数、步长、服务提供者或密钥", cmdEdit},
+		{"next-rotation", "输出下一次验证码轮换的时间", cmdNextRotation},
+		{"seal-store", "用口令为账户文件记录完整性校验信息（HMAC）", cmdSealStore},
+		{"verify-store", "校验账户文件是否被篡改或损坏", cmdVerifyStore},
+		{"audit", "检查账户是否偏离 RFC 6238 常见默认配置", cmdAudit},
+		{"detect-upgrade", "根据设备上的验证码检测服务提供方是否更换了算法", cmdDetectUpgrade},
+		{"code", "直接由密钥计算验证码（不保存账户）", cmdCode},
+		{"verify-secret", "直接用密钥验证标准输入中的验证码（不保存账户）", cmdVerifySecret},
+		{"bulk-code", "按 CSV（label,secret,algo,digits,period）批量输出验证码（不保存账户）", cmdBulkCode},
+		{"probe", "输出计算验证码的每一步中间值（计数器、HMAC、截取偏移），用于排查与其他实现不一致", cmdProbe},
+		{"profiles", "列出已有的配置档（-profile 选择），当前配置档前标 *", cmdProfiles},
+		{"wipe", "覆盖并删除账户文件（紧急销毁密钥，不可恢复）", cmdWipe},
+		{"import-env", "将 " + envAccountPrefix + "<LABEL>=<密钥> 等环境变量中的账户导入账户文件", cmdImportEnv},
+		{"rollback", "用最近的历史版本恢复账户文件（需开启 -backups）", cmdRollback},
+		{"export-bundle", "为一批用户生成新密钥并导出离线注册包（不保存账户）", cmdExportBundle},
+		{"help", "显示帮助", cmdHelp},
+	}
+}
+
+// findCommand 按名称查找子命令
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// printCommands 输出子命令列表
+func printCommands() {
+	out := stdout
+	fmt.Fprintln(out, "用法: go-totp [全局参数] <子命令> [选项]")
+	fmt.Fprintln(out, "\n子命令:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-14s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(out, "\n全局参数:")
+	fmt.Fprintf(out, "  %-14s %s\n", "-profile", "选择配置档，各配置档的账户相互隔离（默认 default，即 ~/.totp_accounts.json）")
+	fmt.Fprintf(out, "  %-14s %s\n", "-file", "使用指定的账户文件；为 - 时从标准输入读取并以只读模式运行（也可通过环境变量 "+accountsJSONEnv+" 直接提供账户内容）")
+	fmt.Fprintf(out, "  %-14s %s\n", "-from-env", "由 "+envAccountPrefix+"<LABEL> 等环境变量构造账户，以只读模式运行，不读写账户文件")
+	fmt.Fprintf(out, "  %-14s %s\n", "-backups N", "保存账户前将原文件保留为历史版本 .1 ~ .N（可用 rollback 恢复），也可在本机配置中设置 backups")
+	fmt.Fprintf(out, "  %-14s %s\n", "-read-only", "只读模式，拒绝任何修改账户文件的操作（也可设置环境变量 "+readOnlyEnv+"=1）")
+	fmt.Fprintf(out, "  %-14s %s\n", "-scrub", "退出时清零内存中缓存的解码密钥和验证码（尽力而为，见 README）")
+	fmt.Fprintf(out, "  %-14s %s\n", "-no-color", "不输出颜色（也可设置环境变量 NO_COLOR）")
+	fmt.Fprintf(out, "  %-14s %s\n", "-force-color", "输出不是终端时也输出颜色，例如管道到 less -R（也可设置环境变量 CLICOLOR_FORCE=1）")
+	fmt.Fprintln(out, "\n使用 go-totp <子命令> -h 查看各子命令的选项")
+}
+
+// legacyUsage 旧版平铺参数的帮助信息
+func legacyUsage(fs *flag.FlagSet) {
+	printCommands()
+	fmt.Fprintln(stdout, "\n兼容参数（将在后续版本移除，请改用子命令）:")
+	fs.PrintDefaults()
+}
+
+// newFlagSet 创建子命令的参数集
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: go-totp %s %s\n\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// flagPassed 判断命令行中是否显式指定了某个参数
+func flagPassed(fs *flag.FlagSet, name string) bool {
+	passed := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
+// addDisplayFlags 注册验证码展示相关参数，默认值取自本机配置
+func addDisplayFlags(fs *flag.FlagSet) func() displayOptions {
+	group := fs.Bool("group", localPrefs.Group || localPrefs.GroupSize > 0, "分组显示验证码，例如 123 456")
+	groupSize := fs.Int("group-size", localPrefs.GroupSize, "分组显示时每组位数（默认按位数自动选择）")
+	return func() displayOptions {
+		return displayOptions{group: *group || flagPassed(fs, "group-size") && *groupSize > 0, groupSize: *groupSize}
+	}
+}
+
+// addSortFlag 注册 -sort 参数，默认值取自本机配置
+func addSortFlag(fs *flag.FlagSet) *string {
+	return fs.String("sort", localPrefs.Sort, "账户排序: label / issuer / recent（最近使用在前），默认按保存顺序")
+}
+
+// accountFlags 账户选择参数：-account 按 label 选择，-index 按 list 中的序号选择
+type accountFlags struct {
+	fs      *flag.FlagSet
+	labels  *string
+	indexes *string
+}
+
+// addAccountFlags 注册账户选择参数
+func addAccountFlags(fs *flag.FlagSet, usage string) accountFlags {
+	return accountFlags{
+		fs:      fs,
+		labels:  fs.String("account", "", usage),
+		indexes: fs.String("index", "", "按 list 中显示的序号选择账户, 可逗号分隔（如 1,3,5）"),
+	}
+}
+
+// selectFrom 按参数选择账户
+// 均未指定时使用本机配置中的默认账户，本机配置也未指定时返回全部账户（显式传入 -account "" 可忽略本机配置）
+func (f accountFlags) selectFrom(accounts []OTPConfig) ([]OTPConfig, error) {
+	if *f.labels != "" && *f.indexes != "" {
+		return nil, fmt.Errorf("-account 与 -index 不能同时使用")
+	}
+	if *f.indexes != "" {
+		return selectByIndex(accounts, *f.indexes)
+	}
+	if localPrefs.Accounts != "" && !flagPassed(f.fs, "account") {
+		selected, err := selectAccounts(accounts, localPrefs.Accounts)
+		if err != nil {
+			return nil, fmt.Errorf("本机配置 %s 中的 accounts: %v", localFileName, err)
+		}
+		return selected, nil
+	}
+	return selectAccounts(accounts, *f.labels)
+}
+
+// single 按参数选择一个账户
+func (f accountFlags) single(accounts []OTPConfig) (OTPConfig, error) {
+	if *f.indexes == "" {
+		return singleAccount(accounts, *f.labels)
+	}
+	selected, err := f.selectFrom(accounts)
+	if err != nil {
+		return OTPConfig{}, err
+	}
+	if len(selected) != 1 {
+		return OTPConfig{}, fmt.Errorf("请只指定一个账户序号")
+	}
+	return selected[0], nil
+}
+
+// singleAccount 按 label 查找一个账户；label 为空且只有一个账户时直接返回该账户
+func singleAccount(accounts []OTPConfig, label string) (OTPConfig, error) {
+	if label == "" {
+		if len(accounts) == 1 {
+			return accounts[0], nil
+		}
+		return OTPConfig{}, fmt.Errorf("请通过 -account 指定一个账户")
+	}
+	selected, err := selectAccounts(accounts, label)
+	if err != nil {
+		return OTPConfig{}, err
+	}
+	if len(selected) != 1 {
+		return OTPConfig{}, fmt.Errorf("请通过 -account 指定一个账户")
+	}
+	return selected[0], nil
+}
+
+func cmdAdd(args []string) error {
+	fs := newFlagSet("add", "[选项] [otpauth://URI]")
+	label := fs.String("label", "", "账户名（手动添加）")
+	secret := fs.String("secret", "", "Base32 密钥（手动添加）")
+	issuer := fs.String("issuer", "", "服务提供者 / 平台名称")
+	algo := fs.String("algo", "SHA1", algoUsage("哈希算法: "))
+	period := fs.Int64("period", 30, "时间步长 (秒)")
+	digits := fs.Int("digits", 6, "验证码位数")
+	name := fs.String("name", "", "显示名称")
+	icon := fs.String("icon", "", "图标（emoji 或短前缀），显示在 list 与 watch 的名称前")
+	clipboard := fs.Bool("clipboard", false, "从系统剪贴板读取 otpauth:// URI")
+	confirm := fs.Bool("confirm", false, "保存前要求输入验证器 App 显示的验证码，确认已完成配置")
+	genSecret := fs.Bool("gen-secret", false, "生成新的随机密钥（需配合 -label），保存前输出密钥与 URI 供录入验证器 App")
+	secretStdin := fs.Bool("secret-stdin", false, "从标准输入读取密钥（需配合 -label 与 -verify-code）")
+	verifyCode := fs.String("verify-code", "", "保存前校验的验证码，不通过则不保存")
+	strict := fs.Bool("strict-rfc", false, "拒绝超出 RFC 6238 常见范围的参数（6/8 位、30 秒、SHA1/SHA256/SHA512、密钥至少 128 位）")
+	dryRun := fs.Bool("dry-run", false, "只输出将添加 / 更新的账户，不写入账户文件")
+	asJSON := fs.Bool("json", false, "-dry-run 时以 JSON 格式输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("一次只能添加一个 URI")
+	}
+	if *asJSON && !*dryRun {
+		return fmt.Errorf("-json 需配合 -dry-run 使用")
+	}
+	uri := fs.Arg(0)
+	if *genSecret {
+		switch {
+		case *secret != "" || uri != "" || *clipboard || *secretStdin:
+			return fmt.Errorf("-gen-secret 不能与 -secret、URI、-clipboard 或 -secret-stdin 同时使用")
+		case *verifyCode != "":
+			return fmt.Errorf("-gen-secret 生成的密钥尚未录入验证器 App，请改用 -confirm 确认")
+		}
+		s, err := totp.GenerateSecret(0)
+		if err != nil {
+			return err
+		}
+		*secret = s
+	}
+	if *secretStdin {
+		switch {
+		case *secret != "" || uri != "" || *clipboard:
+			return fmt.Errorf("-secret-stdin 不能与 -secret、URI 或 -clipboard 同时使用")
+		case *confirm:
+			return fmt.Errorf("-secret-stdin 已占用标准输入，请改用 -verify-code 确认")
+		case *verifyCode == "":
+			return fmt.Errorf("-secret-stdin 需要同时指定 -verify-code")
+		}
+		s, err := readSecretLine(os.Stdin)
+		if err != nil {
+			return err
+		}
+		*secret = s
+	}
+	if *clipboard {
+		content, err := readClipboard()
+		if err != nil {
+			return err
+		}
+		if !isOtpauthURI(content) {
+			return fmt.Errorf("剪贴板内容不是 otpauth:// URI")
+		}
+		uri = content
+	}
+
+	var cfg OTPConfig
+	switch {
+	case uri != "":
+		parsed, err := parseOtpauthURL(uri)
+		if err != nil {
+			return fmt.Errorf("解析 URI 失败: %v", err)
+		}
+		cfg = *parsed
+	case *label != "" && *secret != "":
+		cfg = OTPConfig{
+			Label:     *label,
+			Secret:    *secret,
+			Issuer:    *issuer,
+			Algorithm: totp.Algorithm(strings.ToUpper(*algo)),
+			Period:    *period,
+			Digits:    *digits,
+		}
+		if err := checkAlgorithm(cfg.Algorithm); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("请提供 otpauth:// URI，或同时指定 -label 与 -secret")
+	}
+	cfg.DisplayName = *name
+	cfg.Icon = *icon
+	if err := checkIcon(cfg.Icon); err != nil {
+		return err
+	}
+	if err := validateAccount(cfg); err != nil {
+		return err
+	}
+	if *strict {
+		if err := checkStrictRFC(cfg); err != nil {
+			return err
+		}
+	}
+	if *genSecret {
+		fmt.Fprintf(stdout, "🔑 已生成密钥: %s\n", totp.FormatSecretForDisplay(cfg.Secret))
+		fmt.Fprintf(stdout, "🔗 %s\n", otpauthURI(cfg))
+	}
+	if *verifyCode != "" {
+		if err := checkVerifyCode(cfg, *verifyCode); err != nil {
+			return err
+		}
+	}
+	if *confirm {
+		if err := confirmEnrollment(cfg, os.Stdin); err != nil {
+			return err
+		}
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	if *dryRun {
+		return printPlan(planImport(accounts, []OTPConfig{cfg}), *asJSON)
+	}
+	if err := addAccount(cfg, accountFile); err != nil {
+		return err
+	}
+	return nil
+}
+
+func cmdRemove(args []string) error {
+	fs := newFlagSet("remove", "<label>")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+
+	_, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	return deleteAccount(fs.Arg(0), accountFile)
+}
+
+func cmdList(args []string) error {
+	fs := newFlagSet("list", "[-verbose] [-unused-since 30d] [-sort label|issuer|recent] [-page N [-page-size M]] [-count-only]")
+	verbose := fs.Bool("verbose", false, "显示最近使用时间")
+	unused := fs.String("unused-since", "", "只列出该时长内未使用的账户（如 30d、72h）")
+	sortKey := addSortFlag(fs)
+	byIssuer := fs.Bool("group-by-issuer", false, "按服务提供者分组列出")
+	page := fs.Int("page", 1, "分页输出时的页码（从 1 开始），只指定 -page 时每页 "+strconv.Itoa(defaultPageSize)+" 个")
+	pageSize := fs.Int("page-size", 0, "每页账户数，0 为不分页")
+	countOnly := fs.Bool("count-only", false, "只输出账户总数（-unused-since 过滤后）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkSortKey(*sortKey); err != nil {
+		return err
+	}
+	if *pageSize < 0 {
+		return fmt.Errorf("-page-size 不能为负数: %d", *pageSize)
+	}
+	if flagPassed(fs, "page") && *pageSize == 0 {
+		*pageSize = defaultPageSize
+	}
+
+	opts := listOptions{verbose: *verbose, sort: *sortKey, groupByIssuer: *byIssuer, page: *page, pageSize: *pageSize, countOnly: *countOnly}
+	if *unused != "" {
+		age, err := parseAge(*unused)
+		if err != nil {
+			return err
+		}
+		opts.unusedSince = age
+	}
+	accounts, _, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	return printAccountList(accounts, opts)
+}
+
+func cmdVerify(args []string) error {
+	fs := newFlagSet("verify", "[选项] <验证码>")
+	account := addAccountFlags(fs, "要验证的账户（只有一个账户时可省略）")
+	padZeros := fs.Bool("pad-zeros", false, "为位数不足的验证码补齐前导零")
+	verifyPeriod := fs.Int64("verify-period", 0, "仅本次验证使用的步长（秒），用于排查步长设置是否正确")
+	window := fs.Int("window", 1, "前后允许的时间步数")
+	tolerance := fs.Duration("tolerance", 0, "以时间表示的容忍度（如 90s），按步长向上取整换算，代替 -window")
+	verifyAlgos := fs.String("verify-algos", "", "服务提供方更换算法的过渡期内同时接受的算法（如 SHA1,SHA256），任一匹配即通过；会放宽安全性，过渡期结束后请勿使用")
+	at := fs.String("at", "", "以指定的可信时间验证（RFC3339 或 Unix 秒数），默认本机当前时间")
+	windowReport := fs.Bool("window-report", false, "诊断：不验证，列出按当前窗口设置会被接受的全部验证码（此时不需要验证码参数）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 && !(*windowReport && fs.NArg() == 0) || *window < 0 || *tolerance < 0 {
+		return usageError(fs)
+	}
+	if *tolerance > 0 && flagPassed(fs, "window") {
+		return fmt.Errorf("-window 与 -tolerance 不能同时使用")
+	}
+	var algos []totp.Algorithm
+	if *verifyAlgos != "" {
+		var err error
+		if algos, err = parseAlgorithms(*verifyAlgos); err != nil {
+			return fmt.Errorf("-verify-algos: %v", err)
+		}
+	}
+	t, err := parseAt(*at)
+	if err != nil {
+		return err
+	}
+
+	accounts, _, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	cfg, err := account.single(accounts)
+	if err != nil {
+		return err
+	}
+	opts := verifyOptions{padZeros: *padZeros, period: *verifyPeriod, window: *window, tolerance: *tolerance, algos: algos, at: t}
+	if *windowReport {
+		return printWindowReport(cfg, opts)
+	}
+	if !verifyAccount(cfg, fs.Arg(0), opts) {
+		return errVerifyFailed
+	}
+	recordUse([]OTPConfig{cfg}, time.Now())
+	return nil
+}
+
+func cmdWatch(args []string) error {
+	fs := newFlagSet("watch", "[选项]")
+	account := addAccountFlags(fs, "只显示指定账户, 可逗号分隔")
+	jsonOutput := fs.Bool("json", false, "不显示界面，改为每秒输出一行 JSON 事件（NDJSON）")
+	rotationOnly := fs.Bool("rotation-only", false, "配合 -json，仅在验证码轮换时输出")
+	columns := fs.Int("columns", max(localPrefs.Columns, 1), "按网格排列账户的列数，终端宽度不足时自动减少")
+	big := fs.Bool("big", localPrefs.Big, "以大号数字显示单个账户的验证码，终端太小时退回普通显示")
+	sortKey := addSortFlag(fs)
+	byIssuer := fs.Bool("group-by-issuer", false, "按服务提供者分组显示，每组前显示一行标题")
+	warnFlag := fs.String("warn-threshold", defaultWarnThreshold.String(), "剩余时间不超过该值时进度条变红（秒数如 10s，或步长百分比如 25%）")
+	beepFlag := fs.String("beep-threshold", defaultBeepThreshold.String(), "剩余时间不超过该值时发出提示音（秒数或百分比，不能大于 -warn-threshold）")
+	dispOpts := addDisplayFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if err := checkSortKey(*sortKey); err != nil {
+		return err
+	}
+	warn, err := parseThreshold(*warnFlag)
+	if err != nil {
+		return fmt.Errorf("-warn-threshold: %v", err)
+	}
+	beep, err := parseThreshold(*beepFlag)
+	if err != nil {
+		return fmt.Errorf("-beep-threshold: %v", err)
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	selected, err := account.selectFrom(accounts)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(stdout, "❌ 当前没有任何账户，请使用 go-totp add 添加账户")
+		return nil
+	}
+	selected = sortAccounts(selected, *sortKey)
+	if *byIssuer {
+		selected = groupByIssuer(selected)
+	}
+	if *jsonOutput {
+		return streamAccounts(selected, *rotationOnly)
+	}
+	if err := checkThresholds(selected, warn, beep); err != nil {
+		return err
+	}
+	// 本机配置中的 big 只在单个账户时生效，显式传入 -big 时才报错
+	if *big && len(selected) != 1 && flagPassed(fs, "big") {
+		return fmt.Errorf("-big 只能用于单个账户，请通过 -account 或 -index 指定")
+	}
+	opts := dispOpts()
+	opts.columns = *columns
+	opts.big = *big && len(selected) == 1
+	opts.warn, opts.beep = warn, beep
+	opts.groupByIssuer = *byIssuer
+	watchAccounts(selected, opts, func() ([]OTPConfig, error) {
+		latest, err := readAccountFile(accountFile)
+		if err != nil {
+			return nil, err
+		}
+		latest, err = account.selectFrom(latest)
+		if err != nil {
+			return nil, err
+		}
+		latest = sortAccounts(latest, *sortKey)
+		if *byIssuer {
+			latest = groupByIssuer(latest)
+		}
+		return latest, nil
+	})
+	return nil
+}
+
+func cmdGen(args []string) error {
+	fs := newFlagSet("gen", "[选项]")
+	account := addAccountFlags(fs, "只输出指定账户, 可逗号分隔")
+	jsonOutput := fs.Bool("json", false, "以 JSON 格式输出")
+	stepOffset := fs.Int("step-offset", 0, "输出相对当前第 N 个时间步的验证码（-1 为上一个，1 为下一个）")
+	timeFmt := fs.String("time-format", "", timeFormatUsage)
+	sortKey := addSortFlag(fs)
+	dispOpts := addDisplayFlags(fs)
+	copyCode := fs.Bool("copy", false, "将验证码复制到系统剪贴板（只能选择一个账户）")
+	clipClear := fs.Duration("clip-clear", defaultClipClear, "配合 -copy：等待该时长后清除剪贴板（剪贴板已被其他内容替换时不清除），0 为不清除")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkSortKey(*sortKey); err != nil {
+		return err
+	}
+	tf, err := parseTimeFormat(*timeFmt)
+	if err != nil {
+		return err
+	}
+	if *clipClear < 0 {
+		return fmt.Errorf("-clip-clear 不能为负数: %s", *clipClear)
+	}
+
+	accounts, _, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	selected, err := account.selectFrom(accounts)
+	if err != nil {
+		return err
+	}
+	if *copyCode && len(selected) != 1 {
+		return fmt.Errorf("-copy 只能用于一个账户，请用 -account 或 -index 指定")
+	}
+	selected = sortAccounts(selected, *sortKey)
+	opts := dispOpts()
+	opts.stepOffset = *stepOffset
+	opts.timeFormat = tf
+	if err := printOnce(selected, opts, *jsonOutput); err != nil {
+		return fmt.Errorf("生成失败: %v", err)
+	}
+	recordUse(selected, time.Now())
+	if *copyCode {
+		return copyAndClear(selected[0], opts, *clipClear)
+	}
+	return nil
+}
+
+func cmdDiff(args []string) error {
+	fs := newFlagSet("diff", "[选项] <另一个账户文件>")
+	jsonOutput := fs.Bool("json", false, "以 JSON 格式输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	return diffWithFile(accounts, accountFile, fs.Arg(0), *jsonOutput)
+}
+
+func cmdHOTP(args []string) error {
+	fs := newFlagSet("hotp", "-account <label> (-from N -to M | -next)")
+	account := addAccountFlags(fs, "账户")
+	from := fs.Uint64("from", 0, "起始计数器")
+	to := fs.Uint64("to", 0, "结束计数器（包含）")
+	next := fs.Bool("next", false, "使用账户保存的计数器生成一个验证码并将计数器加 1（多进程安全）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *next {
+		accountFile, err := GetAccountFilePath()
+		if err != nil {
+			return err
+		}
+		counter, code, err := nextHOTP(accountFile, account.single)
+		if err != nil {
+			return fmt.Errorf("生成 HOTP 失败: %v", err)
+		}
+		fmt.Fprintf(stdout, "%d\t%s\n", counter, code)
+		return nil
+	}
+
+	accounts, _, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	cfg, err := account.single(accounts)
+	if err != nil {
+		return err
+	}
+	if err := printHOTPRange(cfg, *from, *to); err != nil {
+		return fmt.Errorf("生成 HOTP 失败: %v", err)
+	}
+	return nil
+}
+
+func cmdRename(args []string) error {
+	fs := newFlagSet("rename", "<label> <显示名称>")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageError(fs)
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	cfg, err := singleAccount(accounts, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return setDisplayName(cfg.Label, fs.Arg(1), accountFile)
+}
+
+func cmdEdit(args []string) error {
+	fs := newFlagSet("edit", "[选项] <label>")
+	algo := fs.String("set-algo", "", algoUsage("新的哈希算法: "))
+	digits := fs.Int("set-digits", 0, "新的验证码位数")
+	period := fs.Int64("set-period", 0, "新的时间步长 (秒)")
+	issuer := fs.String("set-issuer", "", "新的服务提供者")
+	secret := fs.String("set-secret", "", "新的 Base32 密钥")
+	icon := fs.String("set-icon", "", "新的图标（为空时清除）")
+	staticCode := fs.String("set-static-code", "", "设置紧急静态码（为空时清除），需配合 -static-valid-for")
+	staticFor := fs.String("static-valid-for", "", "紧急静态码的有效时长（如 24h、3d）")
+	strict := fs.Bool("strict-rfc", false, "修改后的参数超出 RFC 6238 常见范围时拒绝保存")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+	// 零值在账户中表示默认值，显式指定时视为无效
+	if flagPassed(fs, "set-digits") && *digits == 0 {
+		return fmt.Errorf("验证码位数必须在 1~%d 之间: 0", totp.MaxDigits)
+	}
+	if flagPassed(fs, "set-period") && *period == 0 {
+		return fmt.Errorf("步长必须大于 0: 0")
+	}
+	if err := checkIcon(*icon); err != nil {
+		return err
+	}
+	var staticUntil time.Time
+	if *staticCode != "" {
+		if *staticFor == "" {
+			return fmt.Errorf("设置紧急静态码时必须通过 -static-valid-for 指定有效时长")
+		}
+		d, err := parseAge(*staticFor)
+		if err != nil {
+			return err
+		}
+		staticUntil = time.Now().Add(d).UTC().Truncate(time.Second)
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	cfg, err := singleAccount(accounts, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	// 只修改显式指定的字段
+	apply := func(updated OTPConfig) OTPConfig {
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "set-algo":
+				updated.Algorithm = totp.Algorithm(strings.ToUpper(*algo))
+			case "set-digits":
+				updated.Digits = *digits
+			case "set-period":
+				updated.Period = *period
+			case "set-issuer":
+				updated.Issuer = *issuer
+			case "set-secret":
+				updated.Secret = *secret
+			case "set-icon":
+				updated.Icon = *icon
+			case "set-static-code":
+				updated.StaticCode = *staticCode
+				updated.StaticValidUntil = staticUntil
+			}
+		})
+		return updated
+	}
+	return editAccount(cfg.Label, apply, *strict, accountFile)
+}
+
+func cmdNextRotation(args []string) error {
+	fs := newFlagSet("next-rotation", "-account <label> [-json]")
+	account := addAccountFlags(fs, "账户（只有一个账户时可省略）")
+	jsonOutput := fs.Bool("json", false, "以 JSON 格式输出")
+	timeFmt := fs.String("time-format", "", timeFormatUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	tf, err := parseTimeFormat(*timeFmt)
+	if err != nil {
+		return err
+	}
+
+	accounts, _, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	cfg, err := account.single(accounts)
+	if err != nil {
+		return err
+	}
+	if err := printNextRotation(cfg, *jsonOutput, tf); err != nil {
+		return fmt.Errorf("生成失败: %v", err)
+	}
+	return nil
+}
+
+func cmdSealStore(args []string) error {
+	fs := newFlagSet("seal-store", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	passphrase, err := readPassphrase(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if err := sealStore(accounts, accountFile, passphrase); err != nil {
+		return fmt.Errorf("记录校验信息失败: %v", err)
+	}
+	return nil
+}
+
+func cmdVerifyStore(args []string) error {
+	fs := newFlagSet("verify-store", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	passphrase, err := readPassphrase(os.Stdin)
+	if err != nil {
+		return err
+	}
+	ok, err := verifyStore(accounts, accountFile, passphrase)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errVerifyFailed
+	}
+	return nil
+}
+
+func cmdAudit(args []string) error {
+	fs := newFlagSet("audit", "[-json]")
+	jsonOutput := fs.Bool("json", false, "以 JSON 格式输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	accounts, _, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	if err := printAudit(accounts, *jsonOutput); err != nil {
+		return fmt.Errorf("输出检查结果失败: %v", err)
+	}
+	return nil
+}
+
+func cmdDetectUpgrade(args []string) error {
+	fs := newFlagSet("detect-upgrade", "-account <label> [-yes] <设备上显示的验证码>")
+	account := addAccountFlags(fs, "账户（只有一个账户时可省略）")
+	yes := fs.Bool("yes", false, "发现其他算法匹配时不询问，直接更新")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+
+	accounts, accountFile, err := loadAccounts()
+	if err != nil {
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+	cfg, err := account.single(accounts)
+	if err != nil {
+		return err
+	}
+	return detectUpgrade(cfg, fs.Arg(0), accountFile, os.Stdin, *yes)
+}
+
+func cmdCode(args []string) error {
+	fs := newFlagSet("code", "-secret <base32> [选项]")
+	secret := fs.String("secret", "", "Base32 密钥")
+	algo := fs.String("algo", "SHA1", algoUsage("哈希算法: "))
+	period := fs.Int64("period", 30, "时间步长 (秒)")
+	digits := fs.Int("digits", 6, "验证码位数")
+	at := fs.String("at", "", "计算指定时间的验证码（RFC3339 或 Unix 秒数），默认当前时间")
+	offset := fs.Duration("offset", 0, "在时间基础上偏移，例如 -30s、1m")
+	stepOffset := fs.Int("step-offset", 0, "再偏移 N 个时间步（-1 为上一个验证码），并输出该时间步的有效期")
+	timeFmt := fs.String("time-format", "", timeFormatUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *secret == "" {
+		return usageError(fs)
+	}
+	tf, err := parseTimeFormat(*timeFmt)
+	if err != nil {
+		return err
+	}
+
+	t, err := parseAt(*at)
+	if err != nil {
+		return err
+	}
+	cfg := OTPConfig{
+		Secret:    *secret,
+		Algorithm: totp.Algorithm(strings.ToUpper(*algo)),
+		Period:    *period,
+		Digits:    *digits,
+	}
+	if err := checkAlgorithm(cfg.Algorithm); err != nil {
+		return err
+	}
+	t = t.Add(*offset + time.Duration(int64(*stepOffset)*cfg.Period)*time.Second)
+	code, err := adhocCode(cfg, t)
+	if err != nil {
+		return fmt.Errorf("生成失败: %v", err)
+	}
+	if *stepOffset == 0 {
+		fmt.Fprintln(stdout, code)
+		return nil
+	}
+	start, end := stepRange(t, cfg.Period)
+	fmt.Fprintf(stdout, "%s %s %s\n", code, tf.format(start, time.RFC3339), tf.format(end, time.RFC3339))
+	return nil
+}
+
+func cmdVerifySecret(args []string) error {
+	fs := newFlagSet("verify-secret", "[-secret <base32> | -secret-file <文件>] [选项] < 验证码")
+	secret := fs.String("secret", "", "Base32 密钥（会出现在进程参数中，建议改用 -secret-file 或环境变量 "+secretEnv+"）")
+	secretFile := fs.String("secret-file", "", "从文件读取 Base32 密钥")
+	algo := fs.String("algo", "SHA1", algoUsage("哈希算法: "))
+	period := fs.Int64("period", 30, "时间步长 (秒)")
+	digits := fs.Int("digits", 6, "验证码位数")
+	window := fs.Int("window", 1, "前后允许的时间步数")
+	at := fs.String("at", "", "以指定的可信时间验证（RFC3339 或 Unix 秒数），默认本机当前时间")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *window < 0 {
+		return usageError(fs)
+	}
+
+	key, err := resolveSecret(*secret, *secretFile)
+	if err != nil {
+		return err
+	}
+	code, err := readCodeLine(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("读取验证码失败: %v", err)
+	}
+	cfg := OTPConfig{
+		Secret:    key,
+		Algorithm: totp.Algorithm(strings.ToUpper(*algo)),
+		Period:    *period,
+		Digits:    *digits,
+	}
+	if err := checkAlgorithm(cfg.Algorithm); err != nil {
+		return err
+	}
+	t, err := parseAt(*at)
+	if err != nil {
+		return err
+	}
+	valid, err := adhocVerify(cfg, code, t, *window)
+	if err != nil {
+		return fmt.Errorf("验证失败: %v", err)
+	}
+	if !valid {
+		fmt.Fprintf(stdout, "%s❌ 验证失败%s\n", Red, Reset)
+		return errVerifyFailed
+	}
+	fmt.Fprintf(stdout, "%s✅ 验证成功%s\n", Green, Reset)
+	return nil
+}
+
+func cmdBulkCode(args []string) error {
+	fs := newFlagSet("bulk-code", "[-at <时间>] <CSV 文件 | ->")
+	at := fs.String("at", "", "计算指定时间的验证码（RFC3339 或 Unix 秒数），默认当前时间")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+
+	t, err := parseAt(*at)
+	if err != nil {
+		return err
+	}
+	in := os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("读取 CSV 失败: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	failed, err := bulkCodes(in, t)
+	if err != nil {
+		return fmt.Errorf("读取 CSV 失败: %v", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 行处理失败", failed)
+	}
+	return nil
+}
+
+func cmdProbe(args []string) error {
+	fs := newFlagSet("probe", "[-secret <base32> | -secret-file <文件>] [选项]")
+	secret := fs.String("secret", "", "Base32 密钥（会出现在进程参数中，建议改用 -secret-file 或环境变量 "+secretEnv+"）")
+	secretFile := fs.String("secret-file", "", "从文件读取 Base32 密钥")
+	algo := fs.String("algo", "SHA1", algoUsage("哈希算法: "))
+	period := fs.Int64("period", 30, "时间步长 (秒)")
+	digits := fs.Int("digits", 6, "验证码位数")
+	at := fs.String("at", "", "计算指定时间的中间值（RFC3339 或 Unix 秒数），默认当前时间")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *period <= 0 {
+		return usageError(fs)
+	}
+
+	key, err := resolveSecret(*secret, *secretFile)
+	if err != nil {
+		return err
+	}
+	t, err := parseAt(*at)
+	if err != nil {
+		return err
+	}
+	if t.Unix() < 0 {
+		return fmt.Errorf("时间不能早于 1970-01-01: %s", t.Format(time.RFC3339))
+	}
+	algorithm := totp.Algorithm(strings.ToUpper(*algo))
+	if err := checkAlgorithm(algorithm); err != nil {
+		return err
+	}
+	raw, err := totp.GenerateRaw(key, uint64(t.Unix()/(*period)), *digits, totp.Options{Algorithm: algorithm, Period: *period})
+	if err != nil {
+		return fmt.Errorf("计算失败: %v", err)
+	}
+	printProbe(raw, t, *period)
+	return nil
+}
+
+func cmdProfiles(args []string) error {
+	fs := newFlagSet("profiles", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return printProfiles()
+}
+
+func cmdWipe(args []string) error {
+	fs := newFlagSet("wipe", "[-yes]")
+	yes := fs.Bool("yes", false, "不要求输入确认词（仍会等待 "+wipeDelay.String()+"，期间可按 Ctrl+C 取消）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	accountFile, err := GetAccountFilePath()
+	if err != nil {
+		return err
+	}
+	if readOnly {
+		return errReadOnly
+	}
+	_, err = wipeStore(accountFile, os.Stdin, *yes, wipeDelay)
+	return err
+}
+
+func cmdImportEnv(args []string) error {
+	fs := newFlagSet("import-env", "[-dry-run] [-json]")
+	dryRun := fs.Bool("dry-run", false, "只输出将添加 / 更新的账户，不写入账户文件")
+	asJSON := fs.Bool("json", false, "-dry-run 时以 JSON 格式输出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *asJSON && !*dryRun {
+		return fmt.Errorf("-json 需配合 -dry-run 使用")
+	}
+
+	incoming, err := accountsFromEnv(os.Environ())
+	if err != nil {
+		return err
+	}
+	if len(incoming) == 0 {
+		return fmt.Errorf("没有找到 %s 开头的环境变量", envAccountPrefix)
+	}
+	if *dryRun {
+		accounts, _, err := loadAccounts()
+		if err != nil {
+			return fmt.Errorf("读取账户失败: %v", err)
+		}
+		return printPlan(planImport(accounts, incoming), *asJSON)
+	}
+	accountFile, err := GetAccountFilePath()
+	if err != nil {
+		return err
+	}
+	changed := 0
+	err = updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		// 无变化的账户不写入，保留其使用记录
+		for i, item := range planImport(accounts, incoming) {
+			if item.Action != planUnchanged {
+				accounts, _ = upsertAccount(accounts, incoming[i])
+				changed++
+			}
+		}
+		if changed == 0 {
+			return nil, errNoChange
+		}
+		return accounts, nil
+	})
+	switch {
+	case errors.Is(err, errNoChange):
+		fmt.Fprintf(stdout, "环境变量中的 %d 个账户均无变化\n", len(incoming))
+	case err != nil:
+		return err
+	default:
+		fmt.Fprintf(stdout, "✅ 已从环境变量导入 %d 个账户（%d 个无变化）\n", changed, len(incoming)-changed)
+	}
+	return nil
+}
+
+func cmdRollback(args []string) error {
+	fs := newFlagSet("rollback", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	accountFile, err := GetAccountFilePath()
+	if err != nil {
+		return err
+	}
+	return withStoreLock(accountFile, func() error {
+		return rollbackStore(accountFile)
+	})
+}
+
+func cmdExportBundle(args []string) error {
+	fs := newFlagSet("export-bundle", "[选项] <文件> <label>...")
+	issuer := fs.String("issuer", "", "所有账户的服务提供者名称")
+	encrypt := fs.Bool("encrypt", false, "用口令加密注册包（口令从环境变量 "+bundlePassphraseEnv+" 或标准输入读取）")
+	force := fs.Bool("force", false, "覆盖已存在的文件")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return usageError(fs)
+	}
+	path, labels := fs.Arg(0), fs.Args()[1:]
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		if label == "" || seen[label] {
+			return fmt.Errorf("label 不能为空或重复: %q", label)
+		}
+		seen[label] = true
+	}
+
+	var passphrase string
+	if *encrypt {
+		p, err := promptPassphrase(os.Stdin, bundlePassphraseEnv, "注册包加密口令")
+		if err != nil {
+			return err
+		}
+		passphrase = p
+	}
+	b, err := newBundle(labels, *issuer)
+	if err != nil {
+		return err
+	}
+	if err := writeBundle(path, b, passphrase, *force); err != nil {
+		return fmt.Errorf("写入注册包失败: %v", err)
+	}
+	fmt.Fprintf(stdout, "✅ 已为 %d 个账户生成注册包: %s\n", len(b.Accounts), path)
+	if passphrase == "" {
+		fmt.Fprintf(stdout, "%s⚠️ 注册包中的密钥为明文，请通过安全渠道分发并在用户录入后销毁（或使用 -encrypt）%s\n", Yellow, Reset)
+	}
+	return nil
+}
+
+func cmdHelp(args []string) error {
+	if len(args) > 0 {
+		if c := findCommand(args[0]); c != nil && c.name != "help" {
+			if err := c.run([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return nil
+		}
+	}
+	printCommands()
+	return nil
+}
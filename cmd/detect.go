@@ -0,0 +1,117 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 16:42:51
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// candidateAlgorithms 检测算法升级时依次尝试的算法
+var candidateAlgorithms = []totp.Algorithm{totp.SHA1, totp.SHA256, totp.SHA512}
+
+// matchingAlgorithms 返回能使 code 验证通过的所有算法（其余参数使用账户配置）
+func matchingAlgorithms(cfg OTPConfig, code string, t time.Time) ([]totp.Algorithm, error) {
+	if cfg.Digits == 0 {
+		cfg.Digits = 6
+	}
+	if cfg.Period == 0 {
+		cfg.Period = totp.DefaultStep
+	}
+	var matched []totp.Algorithm
+	for _, algo := range candidateAlgorithms {
+		cfg.Algorithm = algo
+		ok, err := adhocVerify(cfg, code, t, 1)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, algo)
+		}
+	}
+	return matched, nil
+}
+
+// detectUpgrade 根据设备上显示的验证码检查服务提供方是否更换了算法
+// 验证码长度与账户位数不同时同时按验证码长度检查，位数设置错误也能一并发现
+// 发现其他参数匹配时询问是否更新（assumeYes 为 true 时直接更新）
+func detectUpgrade(cfg OTPConfig, code, accountFile string, in io.Reader, assumeYes bool) error {
+	current := cfg.Algorithm
+	if current == "" {
+		current = totp.SHA1
+	}
+	digits := cfg.Digits
+	if digits == 0 {
+		digits = 6
+	}
+	probe := cfg
+	if len(code) != digits && len(code) <= totp.MaxDigits {
+		probe.Digits = len(code)
+	}
+	matched, err := matchingAlgorithms(probe, code, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(matched) == 0 {
+		fmt.Fprintf(stdout, "%s❌ 所有算法均不匹配%s\n", Red, Reset)
+		fmt.Fprintln(stdout, "服务提供方可能同时更换了密钥，请重新添加账户")
+		return nil
+	}
+
+	// 当前算法匹配时优先保留
+	algo := matched[0]
+	if slices.Contains(matched, current) {
+		algo = current
+	}
+	newDigits := digits
+	if probe.Digits != cfg.Digits {
+		newDigits = probe.Digits
+	}
+	if algo == current && newDigits == digits {
+		fmt.Fprintf(stdout, "%s✅ 当前算法 %s 验证通过，无需更新%s\n", Green, current, Reset)
+		return nil
+	}
+
+	var changes []string
+	if algo != current {
+		fmt.Fprintf(stdout, "%s⚠️ 当前算法 %s 不匹配，但 %s 匹配，服务提供方可能已升级算法%s\n", Yellow, current, algo, Reset)
+		changes = append(changes, fmt.Sprintf("算法 %s -> %s", current, algo))
+	}
+	if newDigits != digits {
+		fmt.Fprintf(stdout, "%s⚠️ 验证码为 %d 位，按 %d 位计算时匹配，账户的位数设置（%d 位）可能有误%s\n", Yellow, newDigits, newDigits, digits, Reset)
+		changes = append(changes, fmt.Sprintf("位数 %d -> %d", digits, newDigits))
+	}
+	summary := strings.Join(changes, "，")
+	if !assumeYes {
+		fmt.Fprintf(stdout, "是否将 %s 的%s？[y/N]: ", cfg.Label, summary)
+		line, _ := bufio.NewReader(in).ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(stdout, "未修改")
+			return nil
+		}
+	}
+	// 询问期间不持有锁，确认后在锁内修改最新内容
+	err = updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		i := accountIndex(accounts, cfg.Label)
+		if i < 0 {
+			return nil, fmt.Errorf("账户不存在: %s", cfg.Label)
+		}
+		accounts[i].Algorithm = algo
+		if newDigits != digits {
+			accounts[i].Digits = newDigits
+		}
+		return accounts, nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ 已更新: %s\n", summary)
+	return nil
+}
@@ -0,0 +1,126 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 09:40:18
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// accountDiff 两个账户文件的比较结果（不包含任何密钥内容）
+type accountDiff struct {
+	Version schemaVersion `json:"version"`
+	OnlyInA []string      `json:"only_in_a"`
+	OnlyInB []string      `json:"only_in_b"`
+	Changed []changedItem `json:"changed"`
+}
+
+// changedItem 两边都存在但参数不同的账户
+type changedItem struct {
+	Label  string   `json:"label"`
+	Fields []string `json:"fields"`
+}
+
+// diffFields 逐字段比较两个同名账户，返回不同的字段名
+// 密钥只报告“是否不同”，不输出内容；仅大小写、空格或补位不同的密钥视为相同
+func diffFields(a, b OTPConfig) []string {
+	var fields []string
+	if totp.NormalizeSecret(a.Secret) != totp.NormalizeSecret(b.Secret) {
+		fields = append(fields, "secret")
+	}
+	if a.Algorithm != b.Algorithm {
+		fields = append(fields, "algorithm")
+	}
+	if a.Period != b.Period {
+		fields = append(fields, "period")
+	}
+	if a.Digits != b.Digits {
+		fields = append(fields, "digits")
+	}
+	if a.Issuer != b.Issuer {
+		fields = append(fields, "issuer")
+	}
+	if a.DisplayName != b.DisplayName {
+		fields = append(fields, "display_name")
+	}
+	if a.Icon != b.Icon {
+		fields = append(fields, "icon")
+	}
+	if a.StaticCode != b.StaticCode || !a.StaticValidUntil.Equal(b.StaticValidUntil) {
+		fields = append(fields, "static_code")
+	}
+	return fields
+}
+
+// diffAccounts 比较两组账户，结果按 A 的顺序（仅在 B 中的按 B 的顺序）排列
+func diffAccounts(a, b []OTPConfig) accountDiff {
+	result := accountDiff{OnlyInA: []string{}, OnlyInB: []string{}, Changed: []changedItem{}}
+
+	bByLabel := make(map[string]OTPConfig, len(b))
+	for _, acc := range b {
+		bByLabel[acc.Label] = acc
+	}
+	inA := make(map[string]bool, len(a))
+	for _, acc := range a {
+		inA[acc.Label] = true
+		other, ok := bByLabel[acc.Label]
+		if !ok {
+			result.OnlyInA = append(result.OnlyInA, acc.Label)
+			continue
+		}
+		if fields := diffFields(acc, other); len(fields) > 0 {
+			result.Changed = append(result.Changed, changedItem{Label: acc.Label, Fields: fields})
+		}
+	}
+	for _, acc := range b {
+		if !inA[acc.Label] {
+			result.OnlyInB = append(result.OnlyInB, acc.Label)
+		}
+	}
+	return result
+}
+
+// printDiff 输出比较结果
+func printDiff(d accountDiff, fileA, fileB string, asJSON bool) error {
+	if asJSON {
+		return printJSON(d)
+	}
+
+	if len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 && len(d.Changed) == 0 {
+		fmt.Fprintf(stdout, "%s✅ 两个账户文件一致%s\n", Green, Reset)
+		return nil
+	}
+	fmt.Fprintf(stdout, "A: %s\nB: %s\n", fileA, fileB)
+	for _, l := range d.OnlyInA {
+		fmt.Fprintf(stdout, "%s- 仅在 A 中: %s%s\n", Red, l, Reset)
+	}
+	for _, l := range d.OnlyInB {
+		fmt.Fprintf(stdout, "%s+ 仅在 B 中: %s%s\n", Green, l, Reset)
+	}
+	for _, c := range d.Changed {
+		fields := make([]string, 0, len(c.Fields))
+		for _, f := range c.Fields {
+			if f == "secret" {
+				f = "密钥不同"
+			}
+			fields = append(fields, f)
+		}
+		fmt.Fprintf(stdout, "%s~ 参数不同: %s (%s)%s\n", Yellow, c.Label, strings.Join(fields, ", "), Reset)
+	}
+	return nil
+}
+
+// diffWithFile 将当前账户与另一个账户文件比较并输出结果
+func diffWithFile(accounts []OTPConfig, accountFile, otherFile string, asJSON bool) error {
+	other, err := readAccountFile(otherFile)
+	if err != nil {
+		return fmt.Errorf("读取对比文件失败: %v", err)
+	}
+	if err := printDiff(diffAccounts(accounts, other), accountFile, otherFile, asJSON); err != nil {
+		return fmt.Errorf("输出差异失败: %v", err)
+	}
+	return nil
+}
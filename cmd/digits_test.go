@@ -0,0 +1,70 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 05:48:09
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+func TestAddEightDigits(t *testing.T) {
+	testHome(t)
+	code, err := totp.GenerateTOTPWithOptions(testSecret, time.Now(), totp.Options{Digits: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, "add", "-label", "bank", "-secret", testSecret, "-digits", "8", "-verify-code", code)
+
+	var results []struct {
+		Code string `json:"code"`
+	}
+	out := mustRun(t, "gen", "-json", "-account", "bank")
+	if err := json.Unmarshal([]byte(out), &results); err != nil || len(results) != 1 {
+		t.Fatalf("解析输出失败: %v\n%s", err, out)
+	}
+	got := results[0].Code
+	if len(got) != 8 {
+		t.Errorf("8 位账户生成的验证码为 %q", got)
+	}
+	if _, err := runCLI(t, "verify", "-account", "bank", got); err != nil {
+		t.Errorf("8 位验证码应通过验证: %v", err)
+	}
+}
+
+func TestCheckVerifyCodeDigits(t *testing.T) {
+	captureOutput(t)
+	cfg := OTPConfig{Label: "bank", Secret: testSecret, Algorithm: totp.SHA1, Period: 30, Digits: 8}
+	code, err := totp.GenerateTOTPWithOptions(cfg.Secret, time.Now(), cfg.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkVerifyCode(cfg, code); err != nil {
+		t.Errorf("8 位账户的 -verify-code 应通过: %v", err)
+	}
+	if err := checkVerifyCode(cfg, code[2:]); err == nil {
+		t.Error("8 位账户不应接受 6 位验证码")
+	}
+	if err := confirmEnrollment(cfg, strings.NewReader(code+"\n")); err != nil {
+		t.Errorf("8 位账户的 -confirm 应通过: %v", err)
+	}
+}
+
+func TestAddRejectsInvalidDigits(t *testing.T) {
+	testHome(t)
+	for _, digits := range []string{"-1", "10"} {
+		if _, err := runCLI(t, "add", "-label", "bad", "-secret", testSecret, "-digits", digits); err == nil {
+			t.Errorf("-digits %s 应被拒绝", digits)
+		}
+	}
+	if out := mustRun(t, "list"); strings.Contains(out, "bad") {
+		t.Errorf("位数无效的账户不应被保存: %s", out)
+	}
+	if _, err := runCLI(t, "code", "-secret", testSecret, "-digits", "10"); err == nil {
+		t.Error("code -digits 10 应返回错误")
+	}
+}
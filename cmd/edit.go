@@ -0,0 +1,92 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 18:24:37
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// algoUsage 算法参数的帮助文字，列出当前构建支持的全部算法
+func algoUsage(prefix string) string {
+	names := make([]string, 0, 4)
+	for _, a := range totp.SupportedAlgorithms() {
+		names = append(names, string(a))
+	}
+	return prefix + strings.Join(names, "/")
+}
+
+// checkAlgorithm 校验算法是否受当前构建支持
+func checkAlgorithm(algo totp.Algorithm) error {
+	if !totp.IsSupported(algo) {
+		return fmt.Errorf("不支持的算法: %s（%s）", algo, algoUsage("可选: "))
+	}
+	return nil
+}
+
+// validateAccount 校验账户参数：算法受支持、位数在 1~MaxDigits 之间、步长为正、密钥可以解码
+// 早期版本保存的账户可能缺少算法、位数或步长（零值），按默认值处理
+func validateAccount(cfg OTPConfig) error {
+	if cfg.Algorithm != "" {
+		if err := checkAlgorithm(cfg.Algorithm); err != nil {
+			return err
+		}
+	}
+	if cfg.Digits < 0 || cfg.Digits > totp.MaxDigits {
+		return fmt.Errorf("验证码位数必须在 1~%d 之间: %d", totp.MaxDigits, cfg.Digits)
+	}
+	if cfg.Period < 0 {
+		return fmt.Errorf("步长必须大于 0: %d", cfg.Period)
+	}
+	if err := totp.CheckSecret(cfg.Secret, totp.Options{}); err != nil {
+		return fmt.Errorf("密钥无效 (%s): %v", redactSecret(cfg.Secret), err)
+	}
+	return nil
+}
+
+// checkStrictRFC -strict-rfc：拒绝主流验证器 App 可能不兼容的账户参数
+func checkStrictRFC(cfg OTPConfig) error {
+	if err := totp.CheckRFC(cfg.Secret, cfg.Digits, cfg.options()); err != nil {
+		return fmt.Errorf("%v（去掉 -strict-rfc 可保存非标准账户）", err)
+	}
+	return nil
+}
+
+// editAccount 在账户文件锁内对 label 账户的最新内容执行 apply 并保存，输出修改了哪些字段（密钥只提示已修改）
+// strict 为 true 时修改后的参数须符合 -strict-rfc
+func editAccount(label string, apply func(OTPConfig) OTPConfig, strict bool, accountFile string) error {
+	var fields []string
+	err := updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		i := accountIndex(accounts, label)
+		if i < 0 {
+			return nil, fmt.Errorf("账户不存在: %s", label)
+		}
+		updated := apply(accounts[i])
+		if fields = diffFields(accounts[i], updated); len(fields) == 0 {
+			return nil, errNoChange
+		}
+		if strict {
+			if err := checkStrictRFC(updated); err != nil {
+				return nil, err
+			}
+		}
+		if err := validateAccount(updated); err != nil {
+			return nil, err
+		}
+		accounts[i] = updated
+		return accounts, nil
+	})
+	if errors.Is(err, errNoChange) {
+		fmt.Fprintln(stdout, "未指定任何修改")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ 已修改 %s: %s\n", label, strings.Join(fields, ", "))
+	return nil
+}
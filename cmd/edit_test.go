@@ -0,0 +1,37 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 06:38:44
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddStrictRFC(t *testing.T) {
+	testHome(t)
+	const strong = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // 20 字节
+	rejected := map[string][]string{
+		"位数": {"-digits", "7"},
+		"步长": {"-period", "60"},
+		"密钥": {"-secret", testSecret}, // 10 字节，短于 128 位
+	}
+	for name, extra := range rejected {
+		args := append([]string{"add", "-strict-rfc", "-label", "bad", "-secret", strong}, extra...)
+		_, err := runCLI(t, args...)
+		if err == nil || !strings.Contains(err.Error(), "-strict-rfc") {
+			t.Errorf("%s不符合 RFC 时应拒绝并提示 -strict-rfc: %v", name, err)
+		}
+	}
+	if out := mustRun(t, "list"); strings.Contains(out, "bad") {
+		t.Errorf("被拒绝的账户不应保存: %s", out)
+	}
+
+	mustRun(t, "add", "-strict-rfc", "-label", "good", "-secret", strong, "-digits", "8")
+	// 不加 -strict-rfc 时允许非标准参数
+	mustRun(t, "add", "-label", "legacy", "-secret", strong, "-period", "60")
+
+	if _, err := runCLI(t, "edit", "-strict-rfc", "-set-digits", "7", "good"); err == nil {
+		t.Error("edit -strict-rfc 应拒绝修改为 7 位")
+	}
+}
@@ -0,0 +1,96 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 02:10:48
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// envAccountPrefix 以环境变量提供账户时变量名的前缀
+// TOTP_ACCT_<LABEL>=<密钥> 定义一个账户，TOTP_ACCT_<LABEL>_<属性>=<值> 设置其属性
+const envAccountPrefix = "TOTP_ACCT_"
+
+// envAccountAttrs 支持的属性后缀，未设置的属性使用默认值（SHA1、6 位、30 秒）
+// 名称以这些后缀结尾的变量总是被当作属性，因此 label 不能以它们结尾
+var envAccountAttrs = []string{"_ISSUER", "_ALGO", "_DIGITS", "_PERIOD", "_NAME"}
+
+// accountsFromEnv 从 environ（格式同 os.Environ）中解析 TOTP_ACCT_ 开头的账户，按 label 排序
+// label 为前缀之后的部分，保留大小写；只有属性而没有密钥的账户视为错误
+func accountsFromEnv(environ []string) ([]OTPConfig, error) {
+	secrets := make(map[string]string)
+	attrs := make(map[string]map[string]string)
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(name, envAccountPrefix)
+		if !ok || rest == "" {
+			continue
+		}
+		attr := ""
+		for _, suffix := range envAccountAttrs {
+			if label, ok := strings.CutSuffix(rest, suffix); ok && label != "" {
+				rest, attr = label, suffix
+				break
+			}
+		}
+		if attr == "" {
+			secrets[rest] = value
+			continue
+		}
+		if attrs[rest] == nil {
+			attrs[rest] = make(map[string]string)
+		}
+		attrs[rest][attr] = value
+	}
+	for label := range attrs {
+		if _, ok := secrets[label]; !ok {
+			return nil, fmt.Errorf("环境变量中设置了 %s%s 的属性，但缺少密钥 %s%s", envAccountPrefix, label, envAccountPrefix, label)
+		}
+	}
+
+	var accounts []OTPConfig
+	for label, secret := range secrets {
+		cfg := OTPConfig{Label: label, Secret: secret, Algorithm: totp.SHA1, Digits: 6, Period: totp.DefaultStep}
+		for attr, value := range attrs[label] {
+			if err := setEnvAttr(&cfg, attr, strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("%s%s%s: %v", envAccountPrefix, label, attr, err)
+			}
+		}
+		if err := validateAccount(cfg); err != nil {
+			return nil, fmt.Errorf("%s%s: %v", envAccountPrefix, label, err)
+		}
+		accounts = append(accounts, cfg)
+	}
+	slices.SortFunc(accounts, func(a, b OTPConfig) int { return strings.Compare(a.Label, b.Label) })
+	return accounts, nil
+}
+
+// setEnvAttr 按属性后缀设置账户参数
+func setEnvAttr(cfg *OTPConfig, attr, value string) error {
+	switch attr {
+	case "_ISSUER":
+		cfg.Issuer = value
+	case "_NAME":
+		cfg.DisplayName = value
+	case "_ALGO":
+		cfg.Algorithm = totp.Algorithm(strings.ToUpper(value))
+	case "_DIGITS":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("无效的位数: %q", value)
+		}
+		cfg.Digits = n
+	case "_PERIOD":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("无效的步长: %q", value)
+		}
+		cfg.Period = n
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 08:38:52
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+func TestAccountsFromEnv(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"TOTP_ACCT_=ignored",
+		"TOTP_ACCT_GitHub=" + testSecret,
+		"TOTP_ACCT_GitHub_ISSUER=GitHub",
+		"TOTP_ACCT_GitHub_NAME=工作账号",
+		"TOTP_ACCT_aws_prod=" + rfcSecret,
+		"TOTP_ACCT_aws_prod_ALGO=sha256",
+		"TOTP_ACCT_aws_prod_DIGITS= 8 ",
+		"TOTP_ACCT_aws_prod_PERIOD=60",
+		"TOTP_ACCOUNTS_JSON=[]",
+	}
+	accounts, err := accountsFromEnv(environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []OTPConfig{
+		{Label: "GitHub", Issuer: "GitHub", DisplayName: "工作账号", Secret: testSecret, Algorithm: totp.SHA1, Digits: 6, Period: totp.DefaultStep},
+		{Label: "aws_prod", Secret: rfcSecret, Algorithm: totp.SHA256, Digits: 8, Period: 60},
+	}
+	if len(accounts) != len(want) {
+		t.Fatalf("解析出 %d 个账户，期望 %d: %+v", len(accounts), len(want), accounts)
+	}
+	for i, w := range want {
+		a := accounts[i]
+		if a.Label != w.Label || a.Issuer != w.Issuer || a.DisplayName != w.DisplayName || a.Secret != w.Secret ||
+			a.Algorithm != w.Algorithm || a.Digits != w.Digits || a.Period != w.Period {
+			t.Errorf("账户 %d = %+v，期望 %+v", i, a, w)
+		}
+	}
+
+	bad := []struct {
+		name    string
+		environ []string
+	}{
+		{"只有属性没有密钥", []string{"TOTP_ACCT_GITHUB_ISSUER=GitHub"}},
+		{"无效的位数", []string{"TOTP_ACCT_A=" + testSecret, "TOTP_ACCT_A_DIGITS=six"}},
+		{"无效的步长", []string{"TOTP_ACCT_A=" + testSecret, "TOTP_ACCT_A_PERIOD=-30"}},
+		{"无效的密钥", []string{"TOTP_ACCT_A=not-base32!"}},
+	}
+	for _, tt := range bad {
+		if _, err := accountsFromEnv(tt.environ); err == nil {
+			t.Errorf("%s: 应返回错误", tt.name)
+		}
+	}
+	if accounts, err := accountsFromEnv([]string{"HOME=/root"}); err != nil || len(accounts) != 0 {
+		t.Errorf("没有 TOTP_ACCT_ 变量时应返回空列表: %v %v", accounts, err)
+	}
+}
+
+func TestImportEnv(t *testing.T) {
+	home := testHome(t)
+	t.Setenv("TOTP_ACCT_alice", testSecret)
+	t.Setenv("TOTP_ACCT_alice_ISSUER", "Example")
+	t.Setenv("TOTP_ACCT_bob", rfcSecret)
+
+	// -from-env 直接使用环境变量中的账户，不创建账户文件
+	if out := mustRun(t, "-from-env", "list"); !strings.Contains(out, "alice") || !strings.Contains(out, "bob") {
+		t.Errorf("-from-env list 应列出环境变量中的账户:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".totp_accounts.json")); !os.IsNotExist(err) {
+		t.Errorf("-from-env 不应创建账户文件: %v", err)
+	}
+	if _, err := runCLI(t, "-from-env", "add", "-label", "carol", "-secret", testSecret); err == nil {
+		t.Error("-from-env 为只读模式，add 应失败")
+	}
+
+	mustRun(t, "import-env")
+	accounts, _, err := loadAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, a := range accounts {
+		labels = append(labels, a.Label)
+		if a.Label == "alice" && a.Issuer != "Example" {
+			t.Errorf("alice 的服务提供者应为 Example: %q", a.Issuer)
+		}
+	}
+	slices.Sort(labels)
+	if !slices.Equal(labels, []string{"alice", "bob"}) {
+		t.Errorf("导入后的账户 = %v", labels)
+	}
+	if out := mustRun(t, "import-env"); !strings.Contains(out, "均无变化") {
+		t.Errorf("重复导入应提示无变化:\n%s", out)
+	}
+}
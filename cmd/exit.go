@@ -0,0 +1,59 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 03:02:18
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// ExitError 命令以非 0 状态结束：参数错误（Code 2）或验证未通过（Code 1）
+// 嵌入方可据此取得退出码，由 Run 负责真正退出进程；子命令本身从不调用 os.Exit
+type ExitError struct {
+	Code int
+	Err  error
+
+	reported bool // 错误信息或结果已写入输出，Run 不再重复输出
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("退出码 %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// errVerifyFailed 验证未通过，结果已输出
+var errVerifyFailed = &ExitError{Code: 1, Err: errors.New("验证失败"), reported: true}
+
+// usageError 输出子命令帮助并返回参数错误
+func usageError(fs *flag.FlagSet) error {
+	fs.Usage()
+	return &ExitError{Code: 2, Err: fmt.Errorf("%s: 参数错误", fs.Name()), reported: true}
+}
+
+// parseFlags 解析参数；-h 返回 flag.ErrHelp，其余解析错误已由 flag 连同帮助一起输出
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return &ExitError{Code: 2, Err: err, reported: true}
+}
+
+// exitCode 返回 RunWithOutput 的错误对应的进程退出码，以及是否还需要输出错误信息
+func exitCode(err error) (code int, report bool) {
+	var exit *ExitError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0, false
+	case errors.As(err, &exit):
+		return exit.Code, !exit.reported
+	default:
+		return 1, true
+	}
+}
@@ -0,0 +1,64 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 10:30:52
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// maxHOTPRange 单次批量输出 HOTP 的最大数量，防止误输入过大范围
+const maxHOTPRange = 10000
+
+// printHOTPRange 逐行输出 [from, to] 区间内每个计数器的 HOTP 验证码
+// 输出格式为 "计数器<TAB>验证码"，便于管道传给令牌烧录工具
+func printHOTPRange(cfg OTPConfig, from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("计数器范围无效: %d > %d", from, to)
+	}
+	if to-from >= maxHOTPRange {
+		return fmt.Errorf("计数器范围过大: 最多一次输出 %d 个", maxHOTPRange)
+	}
+	digits := cfg.Digits
+	if digits == 0 {
+		digits = 6
+	}
+	for c := from; ; c++ {
+		code, err := totp.GenerateHOTP(cfg.Secret, c, digits, cfg.Algorithm)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%d\t%s\n", c, code)
+		if c == to {
+			break
+		}
+	}
+	return nil
+}
+
+// nextHOTP 在账户文件锁内读取最新计数器、递增并保存，然后用原计数器生成验证码
+// 多个进程同时调用时每个计数器只会被使用一次，且按调用顺序单调递增
+// 账户的选择也必须在锁内进行，不能使用加锁前读到的旧数据
+func nextHOTP(accountFile string, pick func([]OTPConfig) (OTPConfig, error)) (uint64, string, error) {
+	var cfg OTPConfig
+	err := updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		var err error
+		if cfg, err = pick(accounts); err != nil {
+			return nil, err
+		}
+		accounts[accountIndex(accounts, cfg.Label)].Counter = cfg.Counter + 1
+		return accounts, nil
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	// 计数器已持久化后再生成验证码，即使随后崩溃也不会重复使用
+	digits := cfg.Digits
+	if digits == 0 {
+		digits = 6
+	}
+	code, err := totp.GenerateHOTP(cfg.Secret, cfg.Counter, digits, cfg.Algorithm)
+	return cfg.Counter, code, err
+}
@@ -0,0 +1,102 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 03:41:26
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// newTestStore 在临时目录中创建包含 accounts 的账户文件
+func newTestStore(t *testing.T, accounts ...OTPConfig) string {
+	t.Helper()
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	if err := saveAccounts(accounts, path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func pickLabel(label string) func([]OTPConfig) (OTPConfig, error) {
+	return func(accounts []OTPConfig) (OTPConfig, error) {
+		return singleAccount(accounts, label)
+	}
+}
+
+func TestNextHOTPConcurrent(t *testing.T) {
+	path := newTestStore(t, OTPConfig{Label: "h", Secret: testSecret, Algorithm: totp.SHA1, Digits: 6})
+	const n = 20
+	// setDisplayName 会输出提示，多个 goroutine 同时写入 bytes.Buffer 并不安全，这里丢弃输出
+	SetOutput(io.Discard, &stubTerminal{})
+	t.Cleanup(func() { SetOutput(os.Stdout, nil) })
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var counters []uint64
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, code, err := nextHOTP(path, pickLabel("h"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if want, _ := totp.GenerateHOTP(testSecret, c, 6, totp.SHA1); code != want {
+				t.Errorf("计数器 %d 的验证码为 %s，期望 %s", c, code, want)
+			}
+			mu.Lock()
+			counters = append(counters, c)
+			mu.Unlock()
+		}()
+	}
+	// 同时修改同一文件中的其他字段，不应覆盖已递增的计数器
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := setDisplayName("h", fmt.Sprintf("name-%d", i), path); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	slices.Sort(counters)
+	for i, c := range counters {
+		if c != uint64(i) {
+			t.Fatalf("计数器应为 0~%d 且各不相同: %v", n-1, counters)
+		}
+	}
+	accounts, err := readAccountFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accounts[0].Counter != n {
+		t.Errorf("保存的计数器为 %d，期望 %d", accounts[0].Counter, n)
+	}
+}
+
+func TestNextHOTPMonotonic(t *testing.T) {
+	path := newTestStore(t, OTPConfig{Label: "h", Secret: testSecret, Counter: 5})
+	prev := uint64(4)
+	for i := 0; i < 5; i++ {
+		c, _, err := nextHOTP(path, pickLabel("h"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != prev+1 {
+			t.Fatalf("第 %d 次调用得到计数器 %d，期望 %d", i+1, c, prev+1)
+		}
+		prev = c
+	}
+}
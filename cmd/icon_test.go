@@ -0,0 +1,80 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 08:17:45
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIconWidth(t *testing.T) {
+	tests := []struct {
+		icon  string
+		width int
+		ok    bool
+	}{
+		{"", 0, true},
+		{"🔑", 2, true},
+		{"🏦💳", 4, true},
+		{"👨‍👩‍👧", 2, true}, // 零宽连接符组合序列按一个 emoji 计算
+		{"❤️", 1, true},    // 变体选择符不占宽度
+		{"GH", 2, true},
+		{"工作", 4, true},
+		{"🔑🔑🔑", 6, false},
+		{"ABCDE", 5, false},
+		{"\x1b[31m", 0, false},
+		{"a\nb", 0, false},
+	}
+	for _, tt := range tests {
+		err := checkIcon(tt.icon)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkIcon(%q) = %v，期望通过 %v", tt.icon, err, tt.ok)
+		}
+		if tt.ok {
+			if w := stringWidth(tt.icon); w != tt.width {
+				t.Errorf("stringWidth(%q) = %d，期望 %d", tt.icon, w, tt.width)
+			}
+		}
+	}
+
+	// 多列布局按显示宽度截断，不会把 emoji 拆开
+	if got := fitWidth("账户: 🔑 alice", 8); got != "账户: 🔑" {
+		t.Errorf("fitWidth = %q", got)
+	}
+	if got := fitWidth("账户: 🔑 alice", 7); got != "账户: " {
+		t.Errorf("宽度不足时应整个舍弃 emoji: %q", got)
+	}
+}
+
+func TestIconDisplay(t *testing.T) {
+	cfg := OTPConfig{Label: "alice", Issuer: "GitHub", Secret: testSecret, Icon: "🔑"}
+	if got := cfg.titled(); got != "🔑 "+cfg.Name() {
+		t.Errorf("titled() = %q", got)
+	}
+	cfg.Icon = ""
+	if got := cfg.titled(); got != cfg.Name() {
+		t.Errorf("没有图标时 titled() = %q，期望 %q", got, cfg.Name())
+	}
+
+	// 图标只用于本地展示，不写入 otpauth URI
+	cfg.Icon = "🔑"
+	uri := otpauthURI(cfg)
+	plain := cfg
+	plain.Icon = ""
+	if uri != otpauthURI(plain) || strings.Contains(uri, "icon") || strings.Contains(uri, "%F0") {
+		t.Errorf("URI 不应包含图标: %s", uri)
+	}
+
+	testHome(t)
+	out := mustRun(t, "add", "-label", "alice", "-icon", "🔑", "-gen-secret")
+	if !strings.Contains(out, "otpauth://") || strings.Contains(out, "%F0") {
+		t.Errorf("add 输出的 URI 不应包含图标:\n%s", out)
+	}
+	if out := mustRun(t, "list"); !strings.Contains(out, "🔑 alice") {
+		t.Errorf("list 应在名称前显示图标:\n%s", out)
+	}
+	if _, err := runCLI(t, "add", "-label", "bob", "-secret", testSecret, "-icon", "🔑🔑🔑"); err == nil {
+		t.Error("过宽的图标应被拒绝")
+	}
+}
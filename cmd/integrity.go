@@ -0,0 +1,147 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 18:51:06
+package cmd
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// passphraseEnv 完整性校验口令的环境变量，未设置时从标准输入读取
+const passphraseEnv = "TOTP_STORE_PASSPHRASE"
+
+// sealSaltBytes 派生 HMAC 密钥所用盐的长度（字节）
+const sealSaltBytes = 16
+
+// storeSeal 账户文件的完整性校验信息，保存在 <账户文件>.hmac 中
+type storeSeal struct {
+	Version int    `json:"version"`
+	Salt    string `json:"salt"` // 十六进制，派生 HMAC 密钥用
+	HMAC    string `json:"hmac"` // 十六进制，HMAC-SHA256(规范化账户数据)
+}
+
+// sealPath 返回完整性校验文件的路径
+func sealPath(accountFile string) string {
+	return accountFile + ".hmac"
+}
+
+// canonicalAccounts 账户的规范化序列化：按 label 排序，去掉 LastUsedAt、Counter 等运行时字段
+// 保证账户配置不变时结果稳定，日常使用（gen / watch / hotp -next）不会导致校验失败
+func canonicalAccounts(accounts []OTPConfig) ([]byte, error) {
+	sorted := slices.Clone(accounts)
+	for i := range sorted {
+		sorted[i].LastUsedAt = time.Time{}
+		sorted[i].Counter = 0
+	}
+	slices.SortFunc(sorted, func(a, b OTPConfig) int { return strings.Compare(a.Label, b.Label) })
+	return json.Marshal(sorted)
+}
+
+// storeMAC 计算账户数据的 HMAC，密钥由口令和盐经 PBKDF2 派生
+func storeMAC(accounts []OTPConfig, passphrase string, salt []byte) ([]byte, error) {
+	key, err := totp.DeriveSecret([]byte(passphrase), salt, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	data, err := canonicalAccounts(accounts)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// readPassphrase 从环境变量或标准输入读取完整性校验口令
+func readPassphrase(in io.Reader) (string, error) {
+	return promptPassphrase(in, passphraseEnv, "完整性校验口令")
+}
+
+// promptPassphrase 优先从环境变量 env 读取口令，未设置时提示输入 what 并从标准输入读取一行
+func promptPassphrase(in io.Reader, env, what string) (string, error) {
+	if p := os.Getenv(env); p != "" {
+		return p, nil
+	}
+	fmt.Fprintf(stdout, "请输入%s: ", what)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	p := strings.TrimRight(line, "\r\n")
+	if p == "" {
+		return "", fmt.Errorf("口令不能为空（也可通过环境变量 %s 提供）", env)
+	}
+	return p, nil
+}
+
+// sealStore 为当前账户计算校验信息并写入 <账户文件>.hmac
+// 有意修改账户后需要重新执行，否则 verify-store 会报告不一致
+func sealStore(accounts []OTPConfig, accountFile, passphrase string) error {
+	if readOnly {
+		return errReadOnly
+	}
+	salt := make([]byte, sealSaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	sum, err := storeMAC(accounts, passphrase, salt)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(storeSeal{Version: 1, Salt: hex.EncodeToString(salt), HMAC: hex.EncodeToString(sum)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sealPath(accountFile), data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ 已记录账户文件校验信息: %s\n", sealPath(accountFile))
+	return nil
+}
+
+// verifyStore 重新计算并比较账户文件的校验信息，返回是否一致
+func verifyStore(accounts []OTPConfig, accountFile, passphrase string) (bool, error) {
+	data, err := os.ReadFile(sealPath(accountFile))
+	if os.IsNotExist(err) {
+		return false, fmt.Errorf("未找到校验信息，请先执行 go-totp seal-store")
+	}
+	if err != nil {
+		return false, err
+	}
+	var seal storeSeal
+	if err := json.Unmarshal(data, &seal); err != nil {
+		return false, fmt.Errorf("校验文件格式错误: %v", err)
+	}
+	salt, err := hex.DecodeString(seal.Salt)
+	if err != nil {
+		return false, fmt.Errorf("校验文件格式错误: %v", err)
+	}
+	expected, err := hex.DecodeString(seal.HMAC)
+	if err != nil {
+		return false, fmt.Errorf("校验文件格式错误: %v", err)
+	}
+	sum, err := storeMAC(accounts, passphrase, salt)
+	if err != nil {
+		return false, err
+	}
+	if !hmac.Equal(sum, expected) {
+		fmt.Fprintf(stdout, "%s❌ 账户文件与校验信息不一致：文件可能被篡改或损坏，也可能是口令错误%s\n", Red, Reset)
+		fmt.Fprintln(stdout, "如果是你自己修改了账户，请确认无误后执行 go-totp seal-store 重新记录")
+		return false, nil
+	}
+	fmt.Fprintf(stdout, "%s✅ 账户文件完整性校验通过%s\n", Green, Reset)
+	return true, nil
+}
@@ -0,0 +1,32 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 00:06:47
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// jsonSchemaVersion -json 输出的格式版本：删除字段或改变字段含义时递增，只新增字段时不变
+const jsonSchemaVersion = 1
+
+// schemaVersion 输出结构体中的 version 字段，总是序列化为 jsonSchemaVersion，构造时无需赋值
+// 所有 -json 输出都使用带 json 标签的结构体，字段顺序即结构体中的声明顺序，不使用 map
+type schemaVersion struct{}
+
+// MarshalJSON 实现 json.Marshaler
+func (schemaVersion) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, jsonSchemaVersion, 10), nil
+}
+
+// printJSON 以缩进格式输出 JSON
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, string(data))
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 07:24:19
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// objectKeys 按出现顺序返回 JSON 对象的顶层字段名
+func objectKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		t.Fatalf("应为 JSON 对象: %s", data)
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
+
+// checkJSONShape 检查输出（单个对象或对象数组）中每个对象的 version 为 1，且字段及顺序与 keys 一致
+func checkJSONShape(t *testing.T, name, out string, keys []string) {
+	t.Helper()
+	var objects []json.RawMessage
+	if err := json.Unmarshal([]byte(out), &objects); err != nil {
+		objects = []json.RawMessage{json.RawMessage(out)}
+	}
+	if len(objects) == 0 {
+		t.Fatalf("%s: 输出为空数组", name)
+	}
+	for _, obj := range objects {
+		var v struct {
+			Version *int `json:"version"`
+		}
+		if err := json.Unmarshal(obj, &v); err != nil {
+			t.Fatalf("%s: 解析失败: %v\n%s", name, err, out)
+		}
+		if v.Version == nil || *v.Version != jsonSchemaVersion {
+			t.Errorf("%s: version 应为 %d: %s", name, jsonSchemaVersion, obj)
+		}
+		if got := objectKeys(t, obj); !slices.Equal(got, keys) {
+			t.Errorf("%s: 字段为 %v，期望 %v", name, got, keys)
+		}
+	}
+}
+
+func TestJSONOutputShape(t *testing.T) {
+	testHome(t)
+	mustRun(t, "add", "-label", "alice", "-secret", testSecret, "-issuer", "Example")
+	other := filepath.Join(t.TempDir(), "other.json")
+	mustRun(t, "-file", other, "add", "-label", "bob", "-secret", testSecret)
+	t.Setenv(envAccountPrefix+"CAROL", testSecret)
+
+	codeKeys := []string{"version", "label", "code", "display", "seconds_left", "start", "end"}
+	planKeys := []string{"version", "label", "action"}
+	tests := []struct {
+		name string
+		args []string
+		keys []string
+	}{
+		{"gen", []string{"gen", "-json"}, codeKeys},
+		{"旧版 -once", []string{"-once", "-json"}, codeKeys},
+		{"next-rotation", []string{"next-rotation", "-json"}, []string{"version", "label", "next_rotation", "seconds_until"}},
+		{"audit", []string{"audit", "-json"}, []string{"version", "label", "issues"}},
+		{"diff", []string{"diff", "-json", other}, []string{"version", "only_in_a", "only_in_b", "changed"}},
+		{"add -dry-run", []string{"add", "-dry-run", "-json", "-label", "dave", "-secret", testSecret}, planKeys},
+		{"import-env -dry-run", []string{"import-env", "-dry-run", "-json"}, planKeys},
+	}
+	for _, tt := range tests {
+		checkJSONShape(t, tt.name, mustRun(t, tt.args...), tt.keys)
+	}
+
+	// watch -json 持续输出直到 Ctrl+C，这里只检查事件结构
+	data, err := json.Marshal(watchEvent{Label: "alice", Code: "123456"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkJSONShape(t, "watch", string(data), []string{"version", "label", "code", "seconds_left", "step"})
+}
@@ -0,0 +1,179 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 15:32:47
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 动态显示的布局参数
+const (
+	headerLines = 2  // 标题 + 分隔线
+	blockLines  = 6  // 每个账户块的行数（服务提供者、账户、算法、验证码、剩余时间、分隔线）
+	blockWidth  = 40 // 每个账户块的显示宽度
+	columnGap   = 2  // 多列显示时列之间的空白
+)
+
+// gridLayout 账户块的网格布局
+type gridLayout struct {
+	columns int
+	groups  []layoutGroup // 按服务提供者分组显示时的各组，为空时不分组
+}
+
+// layoutGroup 分组显示时的一组账户：一行组标题，下面是该组账户块的网格
+type layoutGroup struct {
+	title string
+	start int // 组内第一个账户的下标
+	count int
+}
+
+// newGridLayout 按期望列数和终端宽度计算实际列数
+// 终端宽度未知（width<=0）时按期望列数显示；宽度不足时减少列数，最少为单列
+func newGridLayout(columns, width int) gridLayout {
+	if columns < 1 {
+		columns = 1
+	}
+	if width > 0 {
+		fit := (width + columnGap) / (blockWidth + columnGap)
+		if fit < columns {
+			columns = fit
+		}
+	}
+	if columns < 1 {
+		columns = 1
+	}
+	return gridLayout{columns: columns}
+}
+
+// withIssuerGroups 按服务提供者分组，accounts 须已按 groupByIssuer 排好顺序
+func (g gridLayout) withIssuerGroups(accounts []OTPConfig) gridLayout {
+	g.groups = nil
+	for i, cfg := range accounts {
+		title := issuerTitle(cfg.Issuer)
+		if n := len(g.groups); n > 0 && g.groups[n-1].title == title {
+			g.groups[n-1].count++
+			continue
+		}
+		g.groups = append(g.groups, layoutGroup{title: title, start: i, count: 1})
+	}
+	return g
+}
+
+// gridHeight 返回 n 个账户块排成网格所占的行数
+func (g gridLayout) gridHeight(n int) int {
+	return (n + g.columns - 1) / g.columns * blockLines
+}
+
+// groupRow 返回第 gi 组标题所在的行
+func (g gridLayout) groupRow(gi int) int {
+	row := headerLines + 1
+	for _, grp := range g.groups[:gi] {
+		row += 1 + g.gridHeight(grp.count)
+	}
+	return row
+}
+
+// origin 返回第 i 个账户块左上角的位置（行、列均从 1 开始）
+// 分组显示时每组先占一行标题，组内账户从新的一行开始排列
+func (g gridLayout) origin(i int) (row, col int) {
+	row = headerLines + 1
+	for gi, grp := range g.groups {
+		if i < grp.start+grp.count {
+			row = g.groupRow(gi) + 1
+			i -= grp.start
+			break
+		}
+	}
+	row += (i / g.columns) * blockLines
+	col = 1 + (i%g.columns)*(blockWidth+columnGap)
+	return row, col
+}
+
+// footerRow 返回所有账户块之后的第一行
+func (g gridLayout) footerRow(n int) int {
+	if len(g.groups) > 0 {
+		return g.groupRow(len(g.groups))
+	}
+	return headerLines + 1 + g.gridHeight(n)
+}
+
+// terminalSize 返回终端的行数和列数，优先使用环境变量 LINES / COLUMNS，无法获取的一项为 0
+func terminalSize() (rows, cols int) {
+	rows, cols = queryTerminalSize()
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		rows = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		cols = n
+	}
+	return rows, cols
+}
+
+// runeWidth 估算单个字符在终端中的显示宽度（中日韩等宽字符与 emoji 按 2 列计算，
+// 组合符号、变体选择符和零宽连接符按 0 列计算）
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) || (r >= 0xFF00 && r <= 0xFF60):
+		return 2
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return 2
+	}
+	return 1
+}
+
+// zeroWidthJoiner 零宽连接符，emoji 组合序列（如 👨‍👩‍👧）中其后的字符与前面合并显示
+const zeroWidthJoiner = '\u200d'
+
+// glyphWidth 同 runeWidth，但紧跟在零宽连接符之后的字符按 0 列计算
+func glyphWidth(prev, r rune) int {
+	if prev == zeroWidthJoiner {
+		return 0
+	}
+	return runeWidth(r)
+}
+
+// stringWidth 估算字符串在终端中的显示宽度
+func stringWidth(s string) int {
+	w, prev := 0, rune(0)
+	for _, r := range s {
+		w += glyphWidth(prev, r)
+		prev = r
+	}
+	return w
+}
+
+// maxIconWidth 账户图标的最大显示宽度（列），足够放下一个 emoji 或两三个字符的前缀
+const maxIconWidth = 4
+
+// checkIcon 校验账户图标：不含控制字符，显示宽度不超过 maxIconWidth
+// 图标会原样输出到固定行布局中，换行或转义序列会打乱 watch 的光标定位
+func checkIcon(icon string) error {
+	if strings.ContainsFunc(icon, unicode.IsControl) {
+		return fmt.Errorf("图标不能包含控制字符")
+	}
+	if w := stringWidth(icon); w > maxIconWidth {
+		return fmt.Errorf("图标过长: 显示宽度为 %d 列，最多 %d 列", w, maxIconWidth)
+	}
+	return nil
+}
+
+// fitWidth 截断超出 width 列的部分，避免多列显示时覆盖相邻的账户块
+func fitWidth(s string, width int) string {
+	w, prev := 0, rune(0)
+	for i, r := range s {
+		rw := glyphWidth(prev, r)
+		if w+rw > width {
+			return s[:i]
+		}
+		w += rw
+		prev = r
+	}
+	return s
+}
@@ -0,0 +1,162 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 21:14:06
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// localFileName 本机配置文件名，与账户文件放在同一目录
+// 账户文件可以纳入版本管理在多台机器间共享，本机的界面偏好放在这里（建议加入 .gitignore）
+const localFileName = ".totp_local.json"
+
+// localConfig 本机配置：只包含界面偏好，不包含任何账户或密钥
+// 优先级：命令行参数 > 本机配置 > 内置默认值
+type localConfig struct {
+	Accounts  string `json:"accounts,omitempty"`   // gen / watch 默认显示的账户（逗号分隔 label），显式传入 -account 或 -index 时不生效
+	Sort      string `json:"sort,omitempty"`       // list / gen / watch 的默认排序
+	Group     bool   `json:"group,omitempty"`      // 默认分组显示验证码
+	GroupSize int    `json:"group_size,omitempty"` // 默认每组位数
+	Columns   int    `json:"columns,omitempty"`    // watch 默认列数
+	Big       bool   `json:"big,omitempty"`        // watch 单账户时默认大字显示
+	Backups   int    `json:"backups,omitempty"`    // 保存账户时保留的历史版本数（0~99），全局参数 -backups 优先
+}
+
+// localPrefs 当前生效的本机配置，启动时读取
+var localPrefs localConfig
+
+// localConfigPath 本机配置文件路径
+func localConfigPath() (string, error) {
+	accountFile, err := GetAccountFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(accountFile), localFileName), nil
+}
+
+// loadLocalConfig 读取本机配置，文件不存在或账户来自内存时返回空配置
+func loadLocalConfig() (localConfig, error) {
+	if memStore != nil {
+		return localConfig{}, nil
+	}
+	path, err := localConfigPath()
+	if err != nil {
+		return localConfig{}, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return localConfig{}, nil
+	}
+	if err != nil {
+		return localConfig{}, err
+	}
+	var cfg localConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return localConfig{}, fmt.Errorf("解析本机配置 %s 失败: %v", path, err)
+	}
+	if err := checkSortKey(cfg.Sort); err != nil {
+		return localConfig{}, fmt.Errorf("本机配置 %s: %v", path, err)
+	}
+	if _, err := parseBackups(strconv.Itoa(cfg.Backups)); err != nil {
+		return localConfig{}, fmt.Errorf("本机配置 %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// 账户排序方式
+const (
+	sortSaved  = ""       // 账户文件中的保存顺序
+	sortLabel  = "label"  // 按 label
+	sortIssuer = "issuer" // 按服务提供者，相同时按 label
+	sortRecent = "recent" // 最近使用的在前，从未使用的在最后
+)
+
+// checkSortKey 校验排序方式
+func checkSortKey(key string) error {
+	switch key {
+	case sortSaved, sortLabel, sortIssuer, sortRecent:
+		return nil
+	}
+	return fmt.Errorf("不支持的排序方式: %q（可选: label、issuer、recent）", key)
+}
+
+// sortedOrder 返回按 key 排序后的账户下标，排序稳定，下标即 list 中的原序号 - 1
+func sortedOrder(accounts []OTPConfig, key string) []int {
+	order := make([]int, len(accounts))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		a, b := accounts[i], accounts[j]
+		switch key {
+		case sortLabel:
+			return strings.Compare(a.Label, b.Label)
+		case sortIssuer:
+			return cmp.Or(strings.Compare(a.Issuer, b.Issuer), strings.Compare(a.Label, b.Label))
+		case sortRecent:
+			return b.LastUsedAt.Compare(a.LastUsedAt)
+		}
+		return 0
+	})
+	return order
+}
+
+// sortAccounts 返回按 key 排序后的账户副本
+func sortAccounts(accounts []OTPConfig, key string) []OTPConfig {
+	sorted := make([]OTPConfig, 0, len(accounts))
+	for _, i := range sortedOrder(accounts, key) {
+		sorted = append(sorted, accounts[i])
+	}
+	return sorted
+}
+
+// issuerTitle 分组显示时的组标题
+func issuerTitle(issuer string) string {
+	if issuer == "" {
+		return "（未设置服务提供者）"
+	}
+	return issuer
+}
+
+// groupIssuerOrder 将下标按服务提供者归组，各组按在 order 中首次出现的顺序排列，
+// 未设置服务提供者的账户排在最后，组内保持 order 中的顺序
+func groupIssuerOrder(accounts []OTPConfig, order []int) []int {
+	rank := make(map[string]int)
+	for _, i := range order {
+		if issuer := accounts[i].Issuer; issuer != "" {
+			if _, ok := rank[issuer]; !ok {
+				rank[issuer] = len(rank)
+			}
+		}
+	}
+	rankOf := func(i int) int {
+		if r, ok := rank[accounts[i].Issuer]; ok {
+			return r
+		}
+		return len(rank)
+	}
+	grouped := slices.Clone(order)
+	slices.SortStableFunc(grouped, func(a, b int) int { return cmp.Compare(rankOf(a), rankOf(b)) })
+	return grouped
+}
+
+// groupByIssuer 返回按服务提供者归组后的账户副本
+func groupByIssuer(accounts []OTPConfig) []OTPConfig {
+	order := make([]int, len(accounts))
+	for i := range order {
+		order[i] = i
+	}
+	grouped := make([]OTPConfig, 0, len(accounts))
+	for _, i := range groupIssuerOrder(accounts, order) {
+		grouped = append(grouped, accounts[i])
+	}
+	return grouped
+}
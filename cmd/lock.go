@@ -0,0 +1,28 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 15:06:12
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// withStoreLock 持有账户文件的独占锁执行 fn，用于跨进程的“读取-修改-写入”
+// 锁加在旁边的 <账户文件>.lock 上而不是账户文件本身，保存方式变化时锁依然有效
+func withStoreLock(accountFile string, fn func() error) error {
+	if readOnly {
+		return errReadOnly
+	}
+	f, err := os.OpenFile(accountFile+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("打开锁文件失败: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("锁定账户文件失败: %w", err)
+	}
+	defer unlockFile(f)
+	return fn()
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 15:06:12
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 阻塞直到取得文件的独占锁（flock）
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// unlockFile 释放文件锁
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
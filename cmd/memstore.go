@@ -0,0 +1,70 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 00:19:33
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// accountsJSONEnv 直接提供账户文件内容的环境变量，适合没有可写账户文件的容器环境
+const accountsJSONEnv = "TOTP_ACCOUNTS_JSON"
+
+// storeFile 全局参数 -file 指定的账户文件，为空时按配置档确定，"-" 表示从标准输入读取
+var storeFile string
+
+// storeFromEnv 全局参数 -from-env：由 TOTP_ACCT_ 开头的环境变量构造账户（见 envimport.go）
+var storeFromEnv bool
+
+// memoryStore 只存在于内存中的账户文件内容（-file -、-from-env 或 TOTP_ACCOUNTS_JSON）
+type memoryStore struct {
+	name string // 用于提示的来源名称
+	data []byte
+}
+
+// memStore 非 nil 时从内存读取账户，运行期间强制只读，任何修改都不会写回
+var memStore *memoryStore
+
+// setupStore 按 -file 与环境变量确定账户来源，在解析全局参数后调用
+// -file 与 -from-env 优先于 TOTP_ACCOUNTS_JSON；in 为 -file - 时读取的标准输入
+func setupStore(in io.Reader) error {
+	memStore = nil
+	if storeFile != "" && profile != defaultProfile {
+		return fmt.Errorf("-file 与 -profile 不能同时使用")
+	}
+	if storeFromEnv && (storeFile != "" || profile != defaultProfile) {
+		return fmt.Errorf("-from-env 不能与 -file 或 -profile 同时使用")
+	}
+	switch env := os.Getenv(accountsJSONEnv); {
+	case storeFromEnv:
+		accounts, err := accountsFromEnv(os.Environ())
+		if err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			return fmt.Errorf("-from-env: 没有找到 %s 开头的环境变量", envAccountPrefix)
+		}
+		data, err := encodeStore(accounts)
+		if err != nil {
+			return err
+		}
+		memStore = &memoryStore{name: "$" + envAccountPrefix + "*", data: data}
+	case storeFile == "-":
+		data, err := io.ReadAll(in)
+		if err != nil {
+			return fmt.Errorf("从标准输入读取账户失败: %v", err)
+		}
+		memStore = &memoryStore{name: "<stdin>", data: data}
+	case storeFile == "" && env != "":
+		memStore = &memoryStore{name: "$" + accountsJSONEnv, data: []byte(env)}
+	default:
+		return nil
+	}
+	readOnly = true
+	if _, err := decodeStore(memStore.data); err != nil {
+		return fmt.Errorf("解析 %s 中的账户失败: %v", memStore.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 07:31:56
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// memStoreJSON 内存账户来源使用的账户文件内容
+const memStoreJSON = `{"version": 2, "accounts": [{"label": "alice", "secret": "JBSWY3DPEHPK3PXP", "algorithm": "SHA1", "period": 30, "digits": 6}]}`
+
+// checkMemStore 检查内存账户可正常读取、拒绝修改，且没有创建账户文件
+func checkMemStore(t *testing.T, prefix ...string) {
+	t.Helper()
+	run := func(args ...string) (string, error) {
+		return runCLI(t, append(append([]string{}, prefix...), args...)...)
+	}
+	out, err := run("list")
+	if err != nil || !strings.Contains(out, "alice") {
+		t.Errorf("list 应列出内存中的账户: %v\n%s", err, out)
+	}
+	if _, err := run("add", "-label", "bob", "-secret", testSecret); !errors.Is(err, errReadOnly) {
+		t.Errorf("内存账户应为只读: %v", err)
+	}
+	if _, err := run("remove", "alice"); !errors.Is(err, errReadOnly) {
+		t.Errorf("内存账户应为只读: %v", err)
+	}
+	path, err := profileAccountFile(defaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("不应创建账户文件 %s: %v", path, err)
+	}
+}
+
+func TestStoreFromStdin(t *testing.T) {
+	testHome(t)
+	withStdin(t, memStoreJSON)
+	checkMemStore(t, "-file", "-")
+
+	// -file - 优先于 TOTP_ACCOUNTS_JSON
+	t.Setenv(accountsJSONEnv, `{"version": 2, "accounts": []}`)
+	withStdin(t, memStoreJSON)
+	if out := mustRun(t, "-file", "-", "list"); !strings.Contains(out, "alice") {
+		t.Errorf("-file - 应优先于环境变量: %s", out)
+	}
+
+	withStdin(t, "not json")
+	if _, err := runCLI(t, "-file", "-", "list"); err == nil || !strings.Contains(err.Error(), "<stdin>") {
+		t.Errorf("无效内容应返回带来源的错误: %v", err)
+	}
+}
+
+func TestStoreFromEnvJSON(t *testing.T) {
+	testHome(t)
+	t.Setenv(accountsJSONEnv, memStoreJSON)
+	checkMemStore(t)
+
+	if out := mustRun(t, "gen", "-account", "alice"); !strings.Contains(out, "alice") {
+		t.Errorf("gen 应使用环境变量中的账户: %s", out)
+	}
+
+	t.Setenv(accountsJSONEnv, "[")
+	if _, err := runCLI(t, "list"); err == nil || !strings.Contains(err.Error(), accountsJSONEnv) {
+		t.Errorf("无效内容应返回带来源的错误: %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 10:52:09
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// displayOptions 验证码展示相关选项
+type displayOptions struct {
+	group     bool // 是否分组显示验证码
+	groupSize int  // 每组位数，<=0 时按位数自动选择
+
+	refreshKey bool // 是否支持按 r 键立即刷新（用于提示文字）
+	columns    int  // 动态显示时的期望列数，<=1 为单列
+	big        bool // 单账户大字显示（终端太小时退回普通显示）
+	stepOffset int  // 一次性输出时相对当前时间步的偏移（-1 为上一个验证码）
+
+	timeFormat timeFormat // 一次性输出中有效期时间戳的格式
+
+	groupByIssuer bool // 动态显示时按服务提供者分组，每组前显示一行标题
+
+	warn threshold // 动态显示时进度条变红的剩余时间
+	beep threshold // 动态显示时发出提示音的剩余时间
+}
+
+// formatCode 按展示选项格式化验证码（仅用于显示，原始验证码不变）
+func (o displayOptions) formatCode(code string) string {
+	if !o.group {
+		return code
+	}
+	size := o.groupSize
+	if size <= 0 {
+		// 6 位按 3 位一组，8 位按 4 位一组
+		size = 3
+		if len(code)%4 == 0 && len(code)%3 != 0 {
+			size = 4
+		}
+	}
+	return groupCode(code, size)
+}
+
+// groupCode 每 size 位插入一个空格，例如 "123456" -> "123 456"
+func groupCode(code string, size int) string {
+	if size <= 0 || len(code) <= size {
+		return code
+	}
+	var b strings.Builder
+	for i, r := range code {
+		if i > 0 && i%size == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// codeResult 单个账户的当前验证码（用于 -once / -json 输出）
+type codeResult struct {
+	Version     schemaVersion `json:"version"`
+	Label       string        `json:"label"`
+	Code        string        `json:"code"`    // 原始验证码
+	Display     string        `json:"display"` // 按展示选项格式化后的验证码
+	SecondsLeft int           `json:"seconds_left"`
+	Start       stamp         `json:"start"`
+	End         stamp         `json:"end"`
+}
+
+// currentCodes 计算各账户当前验证码
+func currentCodes(accounts []OTPConfig, opts displayOptions) ([]codeResult, error) {
+	results := make([]codeResult, 0, len(accounts))
+	now := time.Now()
+	for _, cfg := range accounts {
+		t := now
+		if opts.stepOffset != 0 {
+			period := cfg.Period
+			if period <= 0 {
+				period = totp.DefaultStep
+			}
+			t = now.Add(time.Duration(int64(opts.stepOffset)*period) * time.Second)
+		}
+		res, err := totp.At(cfg.Secret, t, cfg.options())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.Label, err)
+		}
+		results = append(results, codeResult{
+			Label:       cfg.Label,
+			Code:        res.Code,
+			Display:     opts.formatCode(res.Code),
+			SecondsLeft: res.SecondsLeft,
+			Start:       stamp{res.Start, opts.timeFormat},
+			End:         stamp{res.End, opts.timeFormat},
+		})
+	}
+	return results, nil
+}
+
+// printOnce 输出一次当前验证码后返回
+func printOnce(accounts []OTPConfig, opts displayOptions, asJSON bool) error {
+	results, err := currentCodes(accounts, opts)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return printJSON(results)
+	}
+	for i, r := range results {
+		if opts.stepOffset != 0 {
+			// 非当前时间步的验证码输出其有效时间范围
+			fmt.Fprintf(stdout, "%s: %s%s%s (有效期 %s ~ %s)\n", accounts[i].Name(), Green, r.Display, Reset,
+				opts.timeFormat.format(r.Start.t, time.TimeOnly), opts.timeFormat.format(r.End.t, time.TimeOnly))
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: %s%s%s (剩余 %d 秒)\n", accounts[i].Name(), Green, r.Display, Reset, r.SecondsLeft)
+	}
+	return nil
+}
+
+// copyAndClear 将账户的验证码（不分组、不打码的原始验证码）复制到剪贴板，clear > 0 时等待后清除
+// 等待期间按 Ctrl+C 退出会跳过清除
+func copyAndClear(cfg OTPConfig, opts displayOptions, clear time.Duration) error {
+	results, err := currentCodes([]OTPConfig{cfg}, opts)
+	if err != nil {
+		return err
+	}
+	code := results[0].Code
+	if err := writeClipboard(code); err != nil {
+		return err
+	}
+	if clear <= 0 {
+		fmt.Fprintf(stdout, "📋 已复制 %s 的验证码到剪贴板\n", cfg.Name())
+		return nil
+	}
+	fmt.Fprintf(stdout, "📋 已复制 %s 的验证码到剪贴板，%s 后清除...\n", cfg.Name(), clear)
+	cleared, err := clearClipboardAfter(code, clear)
+	if err != nil {
+		return fmt.Errorf("清除剪贴板失败: %v", err)
+	}
+	if cleared {
+		fmt.Fprintln(stdout, "🧹 已清除剪贴板")
+	} else {
+		fmt.Fprintln(stdout, "剪贴板内容已变化，未清除")
+	}
+	return nil
+}
+
+// listOptions list 子命令的选项
+type listOptions struct {
+	verbose     bool          // 显示最近使用时间
+	unusedSince time.Duration // >0 时只列出该时长内未使用的账户
+	sort        string        // 排序方式（序号始终为保存顺序）
+
+	groupByIssuer bool // 按服务提供者分组输出
+
+	page      int  // 页码（从 1 开始），pageSize > 0 时有效
+	pageSize  int  // 每页账户数，0 表示不分页
+	countOnly bool // 只输出（过滤后的）账户总数
+}
+
+// defaultPageSize 只指定 -page 时每页的账户数
+const defaultPageSize = 20
+
+// listPage 返回过滤、排序（及分组）后的账户下标中第 page 页的部分，以及总页数
+// 排序是稳定的，同一账户文件多次分页的结果一致；size <= 0 时不分页
+func listPage(order []int, page, size int) ([]int, int, error) {
+	if size <= 0 {
+		return order, 1, nil
+	}
+	pages := max(1, (len(order)+size-1)/size)
+	if page < 1 || page > pages {
+		return nil, pages, fmt.Errorf("页码超出范围: %d（共 %d 页）", page, pages)
+	}
+	start := (page - 1) * size
+	return order[start:min(start+size, len(order))], pages, nil
+}
+
+// printAccountList 输出已保存账户列表
+// 先按 -unused-since 过滤，再排序 / 分组，最后分页
+func printAccountList(accounts []OTPConfig, opts listOptions) error {
+	now := time.Now()
+	order := sortedOrder(accounts, opts.sort)
+	if opts.groupByIssuer {
+		order = groupIssuerOrder(accounts, order)
+	}
+	if opts.unusedSince > 0 {
+		order = slices.DeleteFunc(order, func(i int) bool { return !unusedSince(accounts[i], opts.unusedSince, now) })
+	}
+	if opts.countOnly {
+		fmt.Fprintln(stdout, len(order))
+		return nil
+	}
+	total := len(order)
+	order, pages, err := listPage(order, opts.page, opts.pageSize)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, "已保存账户列表:")
+	group := ""
+	for _, i := range order {
+		a := accounts[i]
+		if opts.groupByIssuer {
+			if title := issuerTitle(a.Issuer); title != group {
+				group = title
+				fmt.Fprintf(stdout, "%s[%s]%s\n", Bold, title, Reset)
+			}
+			fmt.Fprint(stdout, "  ")
+		}
+		// 序号可用于 -index 选择账户（过滤时保持原序号）
+		// 名称按健康状态着色：绿色正常，黄色偏离默认配置（详见 audit），红色密钥无法解码
+		color := checkHealth(a).color()
+		if a.DisplayName != "" {
+			fmt.Fprintf(stdout, "%d. %s%s <%s>%s (%s) [%s]", i+1, color, a.titled(), a.Label, Reset, a.Issuer, a.Algorithm)
+		} else {
+			fmt.Fprintf(stdout, "%d. %s%s%s (%s) [%s]", i+1, color, a.titled(), Reset, a.Issuer, a.Algorithm)
+		}
+		if a.staticCodeActive(now) {
+			fmt.Fprintf(stdout, " %s⚠️ 紧急静态码有效至 %s%s", Yellow, a.StaticValidUntil.Local().Format("2006-01-02 15:04"), Reset)
+		}
+		if opts.verbose {
+			if a.LastUsedAt.IsZero() {
+				fmt.Fprint(stdout, " 最近使用: 从未")
+			} else {
+				fmt.Fprintf(stdout, " 最近使用: %s", a.LastUsedAt.Local().Format("2006-01-02 15:04"))
+			}
+		}
+		fmt.Fprintln(stdout)
+	}
+	if opts.pageSize > 0 {
+		fmt.Fprintf(stdout, "第 %d/%d 页，共 %d 个账户\n", opts.page, pages, total)
+	}
+	return nil
+}
+
+// rotationResult 下一次验证码轮换时间（用于 -json 输出）
+type rotationResult struct {
+	Version      schemaVersion `json:"version"`
+	Label        string        `json:"label"`
+	NextRotation stamp         `json:"next_rotation"`
+	SecondsUntil int           `json:"seconds_until"`
+}
+
+// printNextRotation 输出账户下一次验证码轮换的时间及距今秒数
+// 文本格式为 "<RFC3339 时间> <秒数>"，方便脚本读取后 sleep
+func printNextRotation(cfg OTPConfig, asJSON bool, tf timeFormat) error {
+	res, err := totp.Now(cfg.Secret, cfg.options())
+	if err != nil {
+		return err
+	}
+	r := rotationResult{
+		Label:        cfg.Label,
+		NextRotation: stamp{res.End, tf},
+		SecondsUntil: int(math.Ceil(time.Until(res.End).Seconds())),
+	}
+	if asJSON {
+		return printJSON(r)
+	}
+	fmt.Fprintf(stdout, "%s %d\n", tf.format(res.End, time.RFC3339), r.SecondsUntil)
+	return nil
+}
+
+// watchEvent 动态显示的 JSON 事件（每行一个）
+type watchEvent struct {
+	Version     schemaVersion `json:"version"`
+	Label       string        `json:"label"`
+	Code        string        `json:"code"`
+	SecondsLeft int           `json:"seconds_left"`
+	Step        int64         `json:"step"`
+}
+
+// streamAccounts 以 NDJSON 形式持续输出验证码，直到收到 Ctrl+C
+// rotationOnly 为 true 时只在验证码轮换时输出，否则每秒输出一次
+func streamAccounts(accounts []OTPConfig, rotationOnly bool) error {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	usage := &usageRecorder{accounts: accounts}
+	usage.tick(time.Now())
+	defer func() { usage.flush(time.Now()) }()
+
+	// 每个事件单独写出一行，输出为 os.Stdout 时不经过缓冲，消费者可立即读到
+	enc := json.NewEncoder(stdout)
+	lastStep := make(map[string]int64, len(accounts))
+	emit := func() error {
+		for _, cfg := range accounts {
+			res, err := totp.Now(cfg.Secret, cfg.options())
+			if err != nil {
+				return fmt.Errorf("%s: %w", cfg.Label, err)
+			}
+			step := res.Start.Unix() / res.Period
+			if prev, ok := lastStep[cfg.Label]; rotationOnly && ok && prev == step {
+				continue
+			}
+			lastStep[cfg.Label] = step
+			if err := enc.Encode(watchEvent{Label: cfg.Label, Code: res.Code, SecondsLeft: res.SecondsLeft, Step: step}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := emit(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ticker.C:
+			if err := emit(); err != nil {
+				return err
+			}
+			usage.tick(time.Now())
+		case <-sigChan:
+			return nil
+		}
+	}
+}
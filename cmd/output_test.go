@@ -0,0 +1,138 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 05:34:47
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+func TestCodeStepOffset(t *testing.T) {
+	testHome(t)
+	const at = 1111111109 // 所在时间步为 [1111111080, 1111111110)
+	tests := []struct {
+		offset     string
+		start, end int64
+	}{
+		{"-1", 1111111050, 1111111080},
+		{"1", 1111111110, 1111111140},
+	}
+	for _, tt := range tests {
+		want, err := totp.GenerateTOTPWithOptions(testSecret, time.Unix(tt.start, 0), totp.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := mustRun(t, "code", "-secret", testSecret, "-at", fmt.Sprint(at), "-step-offset", tt.offset, "-time-format", "unix")
+		if got, expect := strings.TrimSpace(out), fmt.Sprintf("%s %d %d", want, tt.start, tt.end); got != expect {
+			t.Errorf("-step-offset %s 输出 %q，期望 %q", tt.offset, got, expect)
+		}
+	}
+}
+
+func TestGenStepOffset(t *testing.T) {
+	testHome(t)
+	mustRun(t, "add", "-label", "alice", "-secret", testSecret)
+	for _, offset := range []int{-1, 1} {
+		before := time.Now()
+		out := mustRun(t, "gen", "-json", "-time-format", "unix", "-step-offset", fmt.Sprint(offset))
+		var results []struct {
+			Code  string `json:"code"`
+			Start int64  `json:"start"`
+			End   int64  `json:"end"`
+		}
+		if err := json.Unmarshal([]byte(out), &results); err != nil || len(results) != 1 {
+			t.Fatalf("解析输出失败: %v\n%s", err, out)
+		}
+		r := results[0]
+		if r.End-r.Start != 30 {
+			t.Errorf("有效期应为一个时间步: %d ~ %d", r.Start, r.End)
+		}
+		// 输出的时间步与当前时间步相差 offset 个（允许测试期间恰好跨越时间步）
+		current := before.Unix() / 30
+		if step := r.Start / 30; step != current+int64(offset) && step != current+int64(offset)+1 {
+			t.Errorf("-step-offset %d 的时间步为 %d，当前为 %d", offset, step, current)
+		}
+		want, err := totp.GenerateTOTPWithOptions(testSecret, time.Unix(r.Start, 0), totp.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Code != want {
+			t.Errorf("-step-offset %d 的验证码 %s 与时间步 %d 的验证码 %s 不一致", offset, r.Code, r.Start/30, want)
+		}
+	}
+}
+
+func TestListPage(t *testing.T) {
+	order := []int{0, 1, 2, 3, 4, 5, 6}
+	tests := []struct {
+		page, size int
+		want       []int
+		pages      int
+	}{
+		{1, 0, order, 1},
+		{1, 3, []int{0, 1, 2}, 3},
+		{2, 3, []int{3, 4, 5}, 3},
+		{3, 3, []int{6}, 3}, // 最后一页不满
+		{1, 7, order, 1},    // 恰好一页
+		{2, 7, nil, 1},
+		{0, 3, nil, 3},
+		{4, 3, nil, 3},
+	}
+	for _, tt := range tests {
+		got, pages, err := listPage(order, tt.page, tt.size)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("page=%d size=%d 应返回页码超出范围的错误", tt.page, tt.size)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) || pages != tt.pages {
+			t.Errorf("page=%d size=%d = %v（共 %d 页）%v，期望 %v（共 %d 页）", tt.page, tt.size, got, pages, err, tt.want, tt.pages)
+		}
+	}
+	if got, pages, err := listPage(nil, 1, 3); err != nil || len(got) != 0 || pages != 1 {
+		t.Errorf("没有账户时第 1 页应为空: %v %d %v", got, pages, err)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	testHome(t)
+	for _, label := range []string{"e", "b", "d", "a", "c"} {
+		mustRun(t, "add", "-label", label, "-secret", testSecret)
+	}
+	if out := strings.TrimSpace(mustRun(t, "list", "-count-only")); out != "5" {
+		t.Errorf("-count-only 输出 %q，期望 5", out)
+	}
+
+	// 按名称排序后分页：a b | c d | e
+	pages := map[string][]string{"1": {"a", "b"}, "2": {"c", "d"}, "3": {"e"}}
+	for page, want := range pages {
+		out := mustRun(t, "list", "-sort", "label", "-page", page, "-page-size", "2")
+		var got []string
+		for _, line := range strings.Split(out, "\n") {
+			// 账户行形如 "4. a () [SHA1]"
+			if _, rest, ok := strings.Cut(line, ". "); ok {
+				got = append(got, strings.Fields(rest)[0])
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("第 %s 页为 %v，期望 %v\n%s", page, got, want, out)
+		}
+		if !strings.Contains(out, "第 "+page+"/3 页，共 5 个账户") {
+			t.Errorf("第 %s 页缺少页码信息:\n%s", page, out)
+		}
+	}
+	if _, err := runCLI(t, "list", "-page", "4", "-page-size", "2"); err == nil {
+		t.Error("超出范围的页码应返回错误")
+	}
+	if _, err := runCLI(t, "list", "-page-size", "-1"); err == nil {
+		t.Error("负的 -page-size 应返回错误")
+	}
+}
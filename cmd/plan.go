@@ -0,0 +1,66 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 22:31:09
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 导入计划中的动作
+const (
+	planAdd       = "add"       // 新账户
+	planUpdate    = "update"    // 同名账户存在且参数不同，将被覆盖
+	planUnchanged = "unchanged" // 同名账户存在且参数相同
+)
+
+// planItem 导入计划中的单个账户（不包含任何密钥内容）
+type planItem struct {
+	Version schemaVersion `json:"version"`
+	Label   string        `json:"label"`
+	Action  string        `json:"action"`
+	Fields  []string      `json:"fields,omitempty"` // action 为 update 时变化的字段
+}
+
+// planImport 计算将 incoming 写入 accounts 时每个账户的动作，不修改 accounts
+// 规则与 upsertAccount 一致：按 label 匹配，同名即覆盖
+func planImport(accounts, incoming []OTPConfig) []planItem {
+	byLabel := make(map[string]OTPConfig, len(accounts))
+	for _, acc := range accounts {
+		byLabel[acc.Label] = acc
+	}
+	plan := make([]planItem, 0, len(incoming))
+	for _, cfg := range incoming {
+		old, ok := byLabel[cfg.Label]
+		item := planItem{Label: cfg.Label, Action: planAdd}
+		if ok {
+			item.Action = planUnchanged
+			if item.Fields = diffFields(old, cfg); len(item.Fields) > 0 {
+				item.Action = planUpdate
+			}
+		}
+		byLabel[cfg.Label] = cfg // 同一批中重复的 label 以后出现的为准
+		plan = append(plan, item)
+	}
+	return plan
+}
+
+// printPlan 输出导入计划（-dry-run）
+func printPlan(plan []planItem, asJSON bool) error {
+	if asJSON {
+		return printJSON(plan)
+	}
+	for _, item := range plan {
+		switch item.Action {
+		case planAdd:
+			fmt.Fprintf(stdout, "%s+ 将添加: %s%s\n", Green, item.Label, Reset)
+		case planUpdate:
+			fmt.Fprintf(stdout, "%s~ 将更新: %s (%s)%s\n", Yellow, item.Label, strings.Join(item.Fields, ", "), Reset)
+		default:
+			fmt.Fprintf(stdout, "= 无变化: %s\n", item.Label)
+		}
+	}
+	fmt.Fprintln(stdout, "（-dry-run：未写入账户文件）")
+	return nil
+}
@@ -0,0 +1,105 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 23:38:05
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// defaultProfile 默认配置档，使用原有的 ~/.totp_accounts.json，已有账户无需迁移
+const defaultProfile = "default"
+
+// profileAccountName 其他配置档目录中的账户文件名
+const profileAccountName = "accounts.json"
+
+// profile 当前配置档，由全局参数 -profile 指定
+var profile = defaultProfile
+
+// profileNamePattern 配置档名称：字母或数字开头，只含字母、数字、点、下划线和连字符，不超过 64 个字符
+// 不允许路径分隔符且不能以点开头，名称无法逃逸出配置档目录
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// checkProfileName 校验配置档名称
+func checkProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("无效的配置档名称: %q（只能包含字母、数字、点、下划线和连字符，且以字母或数字开头）", name)
+	}
+	return nil
+}
+
+// profilesDir 非默认配置档的根目录：<用户配置目录>/go-totp（Linux 上为 ~/.config/go-totp）
+func profilesDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取用户配置目录: %w", err)
+	}
+	return filepath.Join(dir, "go-totp"), nil
+}
+
+// profileAccountFile 返回配置档的账户文件路径
+func profileAccountFile(name string) (string, error) {
+	if name == defaultProfile {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("无法获取用户主目录: %w", err)
+		}
+		return filepath.Join(home, ".totp_accounts.json"), nil
+	}
+	dir, err := profilesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name, profileAccountName), nil
+}
+
+// listProfiles 列出已有的配置档：默认配置档总在最前，其余为配置档目录下含账户文件的子目录，按名称排序
+func listProfiles() ([]string, error) {
+	profiles := []string{defaultProfile}
+	dir, err := profilesDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var others []string
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == defaultProfile || checkProfileName(e.Name()) != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, e.Name(), profileAccountName)); err == nil {
+			others = append(others, e.Name())
+		}
+	}
+	slices.Sort(others)
+	return append(profiles, others...), nil
+}
+
+// printProfiles 输出配置档列表，当前配置档前标 *
+func printProfiles() error {
+	profiles, err := listProfiles()
+	if err != nil {
+		return err
+	}
+	for _, name := range profiles {
+		mark := " "
+		if name == profile {
+			mark = "*"
+		}
+		path, err := profileAccountFile(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s %s (%s)\n", mark, name, path)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 05:20:14
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyBlocksMutations(t *testing.T) {
+	testHome(t)
+	mustRun(t, "add", "-label", "alice", "-secret", testSecret)
+	path, err := GetAccountFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mutations := [][]string{
+		{"add", "-label", "bob", "-secret", testSecret},
+		{"remove", "alice"},
+		{"rename", "alice", "Alice"},
+		{"import-env"},
+	}
+	t.Setenv(envAccountPrefix+"BOB", testSecret)
+	for _, enable := range []string{"flag", "env"} {
+		for _, args := range mutations {
+			if enable == "flag" {
+				args = append([]string{"-read-only"}, args...)
+			} else {
+				t.Setenv(readOnlyEnv, "1")
+			}
+			if _, err := runCLI(t, args...); !errors.Is(err, errReadOnly) {
+				t.Errorf("%s 只读模式下 %v 应返回 errReadOnly: %v", enable, args, err)
+			}
+		}
+		t.Setenv(readOnlyEnv, "")
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("只读模式下账户文件被修改:\n%s", after)
+	}
+
+	// 读取操作照常工作
+	if out := mustRun(t, "-read-only", "list"); !strings.Contains(out, "alice") {
+		t.Errorf("只读模式下 list 应正常输出: %q", out)
+	}
+	if out := mustRun(t, "-read-only", "gen", "-account", "alice"); !strings.Contains(out, "alice") {
+		t.Errorf("只读模式下 gen 应正常输出: %q", out)
+	}
+}
+
+func TestReadOnlyDoesNotCreateStore(t *testing.T) {
+	testHome(t)
+	path := filepath.Join(t.TempDir(), "missing.json")
+	if _, err := runCLI(t, "-read-only", "-file", path, "list"); err == nil {
+		t.Error("只读模式下账户文件不存在时应返回错误")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("只读模式下不应创建账户文件: %v", err)
+	}
+
+	// 非只读模式下照常创建空文件
+	mustRun(t, "-file", path, "list")
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("应自动创建账户文件: %v", err)
+	}
+}
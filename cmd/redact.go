@@ -0,0 +1,33 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 20:38:52
+package cmd
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redactKeep 脱敏后首尾各保留的字符数
+const redactKeep = 2
+
+// redactSecret 密钥脱敏：只保留首尾各 2 个字符，其余用 * 代替
+// 过短的密钥全部遮盖，避免保留的字符占比过高
+func redactSecret(secret string) string {
+	secret = strings.TrimSpace(secret)
+	if len(secret) <= 4*redactKeep {
+		return "****"
+	}
+	return secret[:redactKeep] + "****" + secret[len(secret)-redactKeep:]
+}
+
+// uriSecretParam otpauth:// URI 中的 secret 参数（不区分大小写）
+var uriSecretParam = regexp.MustCompile(`(?i)([?&]secret=)([^&#]*)`)
+
+// redactURI 将 URI 中的 secret 参数脱敏，用于必须引用 URI 的错误信息
+func redactURI(uri string) string {
+	return uriSecretParam.ReplaceAllStringFunc(uri, func(m string) string {
+		parts := uriSecretParam.FindStringSubmatch(m)
+		return parts[1] + redactSecret(parts[2])
+	})
+}
@@ -0,0 +1,69 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 06:18:25
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+func TestRedactSecret(t *testing.T) {
+	tests := map[string]string{
+		"JBSWY3DPEHPK3PXP":   "JB****XP",
+		" JBSWY3DPEHPK3PXP ": "JB****XP",
+		"JBSWY3DP":           "****",
+		"":                   "****",
+	}
+	for in, want := range tests {
+		if got := redactSecret(in); got != want {
+			t.Errorf("redactSecret(%q) = %q，期望 %q", in, got, want)
+		}
+	}
+	uri := "otpauth://totp/alice?SECRET=JBSWY3DPEHPK3PXP&issuer=Example"
+	if got := redactURI(uri); got != "otpauth://totp/alice?SECRET=JB****XP&issuer=Example" {
+		t.Errorf("redactURI = %q", got)
+	}
+}
+
+func TestSecretNotInErrors(t *testing.T) {
+	testHome(t)
+	// 含无法解码字符的密钥，保证每条路径都走到错误分支
+	const secret = "MZXW6YTBOI======1"
+	var errs []error
+
+	_, err := parseOtpauthURL("otpauth://totp/%zz?secret=" + secret)
+	errs = append(errs, err)
+	errs = append(errs, validateAccount(OTPConfig{Label: "a", Secret: secret}))
+	_, err = totp.GenerateTOTPWithOptions(secret, time.Now(), totp.Options{})
+	errs = append(errs, err)
+	_, err = totp.At(secret, time.Now(), totp.Options{})
+	errs = append(errs, err)
+	_, _, err = totp.ValidateTOTPSkew(secret, "123456", 30, 1, totp.SHA1)
+	errs = append(errs, err)
+	_, err = totp.WindowCodes(secret, time.Now(), totp.Options{}, 1)
+	errs = append(errs, err)
+	_, err = totp.NewValidator(1).Validate("a", secret, "123456", totp.Options{})
+	errs = append(errs, err)
+
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("第 %d 条路径应返回错误", i)
+			continue
+		}
+		if strings.Contains(err.Error(), secret) || strings.Contains(err.Error(), strings.TrimRight(secret, "=1")) {
+			t.Errorf("错误信息包含密钥明文: %v", err)
+		}
+	}
+
+	out, err := runCLI(t, "add", "-label", "a", "-secret", secret)
+	if err == nil {
+		t.Fatal("无效密钥应添加失败")
+	}
+	if strings.Contains(out+err.Error(), secret) {
+		t.Errorf("命令输出包含密钥明文: %s / %v", out, err)
+	}
+}
@@ -0,0 +1,51 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 17:05:33
+package cmd
+
+import (
+	"os"
+	"time"
+)
+
+// storeWatcher 通过轮询修改时间检测账户文件是否被其他进程修改
+type storeWatcher struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// newStoreWatcher 以账户文件的当前状态为基准创建检测器
+func newStoreWatcher(path string) *storeWatcher {
+	w := &storeWatcher{path: path}
+	w.changed()
+	return w
+}
+
+// changed 判断账户文件自上次调用以来是否发生变化
+func (w *storeWatcher) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false
+	}
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+	return true
+}
+
+// sameAccounts 判断两组账户的显示内容是否相同
+// 只比较影响显示的配置，LastUsedAt、Counter 等运行时字段的变化不需要重绘
+func sameAccounts(a, b []OTPConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Label != b[i].Label || len(diffFields(a[i], b[i])) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -4,23 +4,23 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"net/url"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/wsk20/go-totp/pkg/totp"
 )
 
-// ANSI 颜色码
-const (
+// ANSI 颜色码（关闭颜色输出时为空字符串，见 color.go）
+var (
 	Reset  = "\033[0m"
 	Red    = "\033[31m"
 	Green  = "\033[32m"
@@ -30,19 +30,69 @@ const (
 )
 
 type OTPConfig struct {
-	Label     string         `json:"label"`
-	Secret    string         `json:"secret"`
-	Algorithm totp.Algorithm `json:"algorithm"`
-	Period    int64          `json:"period"`
-	Digits    int            `json:"digits"`
-	Issuer    string         `json:"issuer"`
+	Label       string         `json:"label"`
+	DisplayName string         `json:"display_name,omitempty"` // 显示名称，仅用于展示，不参与 URI
+	Icon        string         `json:"icon,omitempty"`         // 图标（emoji 或短前缀），显示在 list 与 watch 的名称前，不参与 URI
+	Secret      string         `json:"secret"`
+	Algorithm   totp.Algorithm `json:"algorithm"`
+	Period      int64          `json:"period"`
+	Digits      int            `json:"digits"`
+	Issuer      string         `json:"issuer"`
+
+	// Counter HOTP 下一次使用的计数器，由 hotp -next 在文件锁内递增
+	Counter uint64 `json:"counter,omitempty"`
+	// LastUsedAt 最近一次生成或验证成功的时间，用于找出长期未使用的账户
+	LastUsedAt time.Time `json:"last_used_at,omitzero"`
+
+	// StaticCode 紧急静态码（break-glass），在 StaticValidUntil 之前与 TOTP 验证码一样可通过验证
+	StaticCode       string    `json:"static_code,omitempty"`
+	StaticValidUntil time.Time `json:"static_valid_until,omitzero"`
 }
 
-// 工具函数
-func clearScreen() { fmt.Print("\033[H\033[2J") }
-func beep()        { fmt.Print("\a") }
+// staticCodeActive 判断紧急静态码在 now 时是否仍然有效（到期时刻起失效）
+func (c OTPConfig) staticCodeActive(now time.Time) bool {
+	return c.StaticCode != "" && now.Before(c.StaticValidUntil)
+}
+
+// Name 返回用于展示的名称，未设置显示名称时使用 Label
+func (c OTPConfig) Name() string {
+	if c.DisplayName != "" {
+		return c.DisplayName
+	}
+	return c.Label
+}
+
+// titled 返回带图标前缀的展示名称，用于 list 与 watch
+func (c OTPConfig) titled() string {
+	if c.Icon == "" {
+		return c.Name()
+	}
+	return c.Icon + " " + c.Name()
+}
+
+// options 返回生成验证码所需的参数
+func (c OTPConfig) options() totp.Options {
+	return totp.Options{Algorithm: c.Algorithm, Period: c.Period, Digits: c.Digits}
+}
 
-func progressBar(total, left float64) string {
+// beepCooldown 两次提示音之间的最小间隔
+// 多个账户同时进入最后几秒时，每次刷新最多只响一次（略小于 1 秒以容忍 ticker 抖动）
+const beepCooldown = 900 * time.Millisecond
+
+// lastBeep 上一次发出提示音的时间
+var lastBeep time.Time
+
+// beepWithCooldown 在冷却时间外才发出提示音
+func beepWithCooldown(now time.Time) {
+	if now.Sub(lastBeep) < beepCooldown {
+		return
+	}
+	lastBeep = now
+	term.Beep()
+}
+
+// progressBar 剩余时间进度条，剩余不超过 warn 秒时变红，不超过 2*warn 秒时变黄
+func progressBar(total, left, warn float64) string {
 	const barWidth = 20
 	ratio := 1 - (left / total)
 	filled := int(ratio * barWidth)
@@ -50,24 +100,34 @@ func progressBar(total, left float64) string {
 		filled = barWidth
 	}
 	color := Green
-	if left <= total*0.25 {
+	if left <= warn {
 		color = Red
-	} else if left <= total*0.5 {
+	} else if left <= warn*2 {
 		color = Yellow
 	}
 	return fmt.Sprintf("%s%s%s%s", color, strings.Repeat("█", filled), strings.Repeat("░", barWidth-filled), Reset)
 }
 
+// isOtpauthURI 判断是否以 otpauth:// 开头（不区分大小写，部分二维码生成器会输出 OTPAUTH://）
+func isOtpauthURI(uri string) bool {
+	return strings.HasPrefix(strings.ToLower(uri), "otpauth://")
+}
+
 // 解析 otpauth:// URI
 func parseOtpauthURL(uri string) (*OTPConfig, error) {
-	if !strings.HasPrefix(uri, "otpauth://") {
+	if !isOtpauthURI(uri) {
 		return nil, fmt.Errorf("不是有效 otpauth:// URI")
 	}
 	u, err := url.Parse(uri)
 	if err != nil {
+		// url.Error 会带上完整 URI，其中包含密钥
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURI(urlErr.URL)
+		}
 		return nil, err
 	}
-	if u.Host != "totp" {
+	if !strings.EqualFold(u.Host, "totp") {
 		return nil, fmt.Errorf("不支持的类型: %s (仅支持 totp)", u.Host)
 	}
 	label := strings.TrimPrefix(u.Path, "/")
@@ -99,300 +159,444 @@ func parseOtpauthURL(uri string) (*OTPConfig, error) {
 	}, nil
 }
 
-// 去重函数
-func uniqueAccounts(accounts []OTPConfig) []OTPConfig {
-	seen := make(map[string]bool)
-	var result []OTPConfig
-	for _, a := range accounts {
-		if !seen[a.Label] {
-			seen[a.Label] = true
-			result = append(result, a)
+// 显示 TOTP（无闪烁版本）
+// 账户块按 layout 排成网格，首次完整绘制后每次只更新验证码和剩余时间两行
+func displayAccounts(accounts []OTPConfig, opts displayOptions, layout gridLayout, codes *stepCache, firstDraw bool) {
+	if firstDraw {
+		// 第一次完整绘制所有静态信息
+		term.ClearScreen()
+		fmt.Fprintln(stdout, Bold+Cyan+"🔐 多账户动态 TOTP 管理器"+Reset)
+		fmt.Fprintln(stdout, strings.Repeat("=", blockWidth))
+		for i, cfg := range accounts {
+			issuer := ""
+			if cfg.Issuer != "" {
+				issuer = "服务提供者: " + cfg.Issuer
+			}
+			lines := []string{
+				issuer, // 没有服务提供者时留空，保持每个账户块行数一致
+				"账户: " + cfg.titled(),
+				fmt.Sprintf("算法: %s | 步长: %ds", cfg.Algorithm, cfg.Period),
+				"验证码: ",
+				"剩余时间: ",
+				strings.Repeat("-", blockWidth),
+			}
+			row, col := layout.origin(i)
+			for j, line := range lines {
+				term.MoveTo(row+j, col)
+				if layout.columns > 1 {
+					line = fitWidth(line, blockWidth)
+				}
+				fmt.Fprint(stdout, line)
+			}
+		}
+		for gi, grp := range layout.groups {
+			term.MoveTo(layout.groupRow(gi), 1)
+			fmt.Fprint(stdout, Bold+"["+grp.title+"]"+Reset)
+		}
+		term.MoveTo(layout.footerRow(len(accounts)), 1)
+		if opts.refreshKey {
+			fmt.Fprintln(stdout, "按 r 立即刷新 | 按 Ctrl+C 退出")
+		} else {
+			fmt.Fprintln(stdout, "按 Ctrl+C 退出")
 		}
+		return
 	}
-	return result
-}
 
-// GetAccountFilePath 获取平台兼容的 .totp_accounts.json 文件路径
-func GetAccountFilePath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("无法获取用户主目录: %w", err)
-	}
+	now := time.Now()
 
-	// 拼接路径：~/ .totp_accounts.json
-	accountFile := filepath.Join(home, ".totp_accounts.json")
+	for i, cfg := range accounts {
+		// 账户块内第 4 行为“验证码”，第 5 行为“剩余时间”
+		row, col := layout.origin(i)
+		term.MoveTo(row+3, col)
 
-	return accountFile, nil
-}
+		code, left, period, err := codes.at(cfg, now)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s❌ 生成失败: %v%s", Red, err, Reset)
+			continue
+		}
 
-// 本地账户操作
-func loadAccounts() ([]OTPConfig, string, error) {
-	// 获取账户文件路径
-	accountFile, err := GetAccountFilePath()
-	if err != nil {
-		return nil, "", fmt.Errorf("❌ 获取账户文件路径失败: %v", err)
-	}
-	if _, err = os.Stat(accountFile); os.IsNotExist(err) {
-		// 文件不存在，创建空文件
-		emptyData := []byte("[]")
-		if err = os.WriteFile(accountFile, emptyData, 0644); err != nil {
-			return nil, "", fmt.Errorf("创建账户文件失败: %v", err)
+		total := float64(period)
+		if float64(left) <= opts.beep.seconds(period) {
+			beepWithCooldown(now)
 		}
-		return []OTPConfig{}, accountFile, nil
+
+		fmt.Fprintf(stdout, "验证码: %s%s%s   ", Green, opts.formatCode(code), Reset)
+		term.MoveTo(row+4, col)
+		fmt.Fprintf(stdout, "剩余时间: %2d 秒 [%s]", left, progressBar(total, float64(left), opts.warn.seconds(period)))
 	}
+	term.MoveTo(layout.footerRow(len(accounts))+1, 1)
+}
 
-	data, err := os.ReadFile(accountFile)
-	if err != nil {
-		return nil, "", err
+// watchAccounts 动态显示验证码，直到收到 Ctrl+C
+// reload 不为 nil 时每秒检查账户文件，被其他进程修改后重新加载，账户有变化则完整重绘
+func watchAccounts(accounts []OTPConfig, dispOpts displayOptions, reload func() ([]OTPConfig, error)) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	// 逐键读取，用于 r 键立即刷新（不支持时仅依赖定时刷新）
+	var keys <-chan byte
+	if restore, err := enableRawInput(); err == nil {
+		defer restore() // 程序退出时恢复终端状态
+		keys = readKeys()
+		dispOpts.refreshKey = true
 	}
 
-	var accounts []OTPConfig
-	if err := json.Unmarshal(data, &accounts); err != nil {
-		return nil, "", err
+	// 隐藏光标
+	term.HideCursor()
+	defer term.ShowCursor() // 程序退出时恢复光标
+
+	usage := &usageRecorder{accounts: accounts}
+	usage.tick(time.Now())
+
+	var store *storeWatcher
+	if reload != nil {
+		if path, err := GetAccountFilePath(); err == nil {
+			store = newStoreWatcher(path)
+		}
 	}
-	return uniqueAccounts(accounts), accountFile, nil
-}
 
-func saveAccounts(accounts []OTPConfig, accountFile string) error {
-	accounts = uniqueAccounts(accounts)
-	data, _ := json.MarshalIndent(accounts, "", "  ")
-	return os.WriteFile(accountFile, data, 0644)
-}
+	// 每秒刷新只重绘倒计时，验证码在时间步变化时才重新生成
+	codes := newStepCache()
+	if scrubOnExit {
+		defer codes.scrub()
+	}
 
-func removeAccount(accounts []OTPConfig, label string) ([]OTPConfig, bool) {
-	for i, a := range accounts {
-		if a.Label == label {
-			return append(accounts[:i], accounts[i+1:]...), true
+	// 按终端大小选择布局，终端大小变化后按 r 重新检测
+	var layout gridLayout
+	var big bool
+	measure := func() {
+		rows, cols := terminalSize()
+		layout = newGridLayout(dispOpts.columns, cols)
+		if dispOpts.groupByIssuer {
+			layout = layout.withIssuerGroups(accounts)
+		}
+		big = dispOpts.big && len(accounts) == 1 && bigFits(accounts[0], rows, cols)
+	}
+	draw := func(firstDraw bool) {
+		if big {
+			displayBig(accounts[0], dispOpts, codes, firstDraw)
+		} else {
+			displayAccounts(accounts, dispOpts, layout, codes, firstDraw)
 		}
 	}
-	return accounts, false
-}
 
-// 显示 TOTP（无闪烁版本）
-func displayAccounts(accounts []OTPConfig, firstDraw bool) {
-	if firstDraw {
-		// 第一次完整绘制所有静态信息
-		clearScreen()
-		fmt.Println(Bold + Cyan + "🔐 多账户动态 TOTP 管理器" + Reset)
-		fmt.Println(strings.Repeat("=", 40))
-		for _, cfg := range accounts {
-			if cfg.Issuer != "" {
-				fmt.Printf("服务提供者: %s\n", cfg.Issuer)
+	measure()
+	draw(true) // 首次完整绘制
+	for {
+		select {
+		case <-ticker.C:
+			if store != nil && store.changed() {
+				// 读取失败（例如正在写入或所选账户已被删除）时保留当前显示
+				if latest, err := reload(); err == nil && len(latest) > 0 && !sameAccounts(accounts, latest) {
+					accounts = latest
+					usage.accounts = latest
+					measure()
+					draw(true)
+				}
+			}
+			draw(false) // 仅局部更新
+			usage.tick(time.Now())
+		case key, ok := <-keys:
+			if !ok {
+				keys = nil // 标准输入已关闭，不再读取
+				continue
+			}
+			if key == 'r' || key == 'R' {
+				// 重新检测终端大小，完整重绘并立即重新计算验证码
+				measure()
+				draw(true)
+				draw(false)
 			}
-			fmt.Printf("账户: %s\n", cfg.Label)
-			fmt.Printf("算法: %s | 步长: %ds\n", cfg.Algorithm, cfg.Period)
-			fmt.Printf("验证码: \n")
-			fmt.Printf("剩余时间: \n")
-			fmt.Println(strings.Repeat("-", 40))
+		case <-sigChan:
+			usage.flush(time.Now())
+			term.ShowCursor()  // 恢复光标显示
+			term.ClearScreen() // 清空屏幕
+			fmt.Fprintln(stdout, "👋 已退出。")
+			return
 		}
-		fmt.Println("按 Ctrl+C 退出")
-		return
 	}
+}
 
-	// 移动光标到标题下方（回顶部，不清屏）
-	// 跳过标题两行 + 分隔线一行
-	fmt.Printf("\033[%d;0H", 3) // 移动到第3行
-
-	now := time.Now()
+// Run 主程序，按 RunWithOutput 的结果设置进程退出码
+// 第一个参数为子命令（add/remove/list/verify/watch/gen 等）时按子命令分发，
+// 否则按旧版平铺参数解析（保留一个版本用于兼容）
+// 子命令前可加全局参数 -profile、-file、-from-env、-backups、-read-only、-scrub、-no-color、-force-color
+func Run() {
+	err := RunWithOutput(os.Args[1:], os.Stdout, nil)
+	code, report := exitCode(err)
+	if report {
+		fmt.Fprintf(errOut, "❌ %v\n", err)
+	}
+	os.Exit(code)
+}
 
-	for i, cfg := range accounts {
-		code, start, end, err := totp.GenerateCurrentTOTP(cfg.Secret, cfg.Algorithm)
-		if err != nil {
-			fmt.Printf("%s❌ 生成失败: %v%s\n", Red, err, Reset)
-			continue
+// runMu 串行化 RunWithOutput：输出目标、终端和全局参数（-profile、-file、-backups、-read-only 等）
+// 都保存在包级变量中，由各子命令直接读取
+var runMu sync.Mutex
+
+// RunWithOutput 以指定参数运行，所有输出写入 w，动态显示的终端控制交给 t
+// t 为 nil 时使用写入 w 的 ANSI 终端；嵌入方或测试可传入自定义实现
+// 不会退出进程：参数错误或验证未通过时返回 *ExitError（含退出码），-h 时返回 flag.ErrHelp
+// 不可重入：每次调用都会重置包级的输出目标和全局参数，同一进程内的并发调用按顺序依次执行
+// （watch 等持续运行的子命令会阻塞其他调用直到退出），子命令内部也不能再调用 RunWithOutput
+func RunWithOutput(args []string, w io.Writer, t Terminal) error {
+	runMu.Lock()
+	defer runMu.Unlock()
+	SetOutput(w, t)
+	readOnly = readOnlyFromEnv()
+	scrubOnExit = false
+	profile = defaultProfile
+	storeFile = ""
+	backupFlag = -1
+	storeFromEnv = false
+	args, color, err := parseGlobalFlags(args)
+	if err != nil {
+		return err
+	}
+	if err := setupStore(os.Stdin); err != nil {
+		return err
+	}
+	setColor(useColor(color, w))
+	if scrubOnExit {
+		defer totp.ClearSecretCache()
+	}
+	prefs, err := loadLocalConfig()
+	if err != nil {
+		return err
+	}
+	localPrefs = prefs
+	if len(args) > 0 {
+		if c := findCommand(args[0]); c != nil {
+			return c.run(args[1:])
 		}
+	}
+	return runLegacy(args)
+}
 
-		total := end.Sub(start).Seconds()
-		left := int(end.Sub(now).Seconds())
-		if left < 0 {
-			left = 0
+// parseGlobalFlags 解析子命令前的全局参数，返回剩余参数
+func parseGlobalFlags(args []string) ([]string, colorMode, error) {
+	color := colorAuto
+	for len(args) > 0 {
+		// 带值的参数：-profile <名称>、-file <路径|->、-backups <N>，也可写作 -profile=<名称>
+		if name, value, ok := strings.Cut(strings.TrimLeft(args[0], "-"), "="); name == "profile" || name == "file" || name == "backups" {
+			if !ok {
+				if len(args) < 2 {
+					return nil, color, fmt.Errorf("-%s 需要指定值", name)
+				}
+				value, args = args[1], args[1:]
+			}
+			switch name {
+			case "file":
+				storeFile = value
+			case "backups":
+				n, err := parseBackups(value)
+				if err != nil {
+					return nil, color, err
+				}
+				backupFlag = n
+			default:
+				if err := checkProfileName(value); err != nil {
+					return nil, color, err
+				}
+				profile = value
+			}
+			args = args[1:]
+			continue
 		}
-		if left <= 5 {
-			beep()
+		switch strings.TrimLeft(args[0], "-") {
+		case "read-only":
+			readOnly = true
+		case "scrub":
+			scrubOnExit = true
+		case "from-env":
+			storeFromEnv = true
+		case "no-color":
+			color = colorNever
+		case "force-color":
+			if color != colorNever { // -no-color 优先
+				color = colorAlways
+			}
+		default:
+			return args, color, nil
 		}
-
-		// 计算当前账户在屏幕上的起始行
-		// 每个账户块为 6 行（含分隔线）
-		startLine := 3 + i*6
-		// 移动到对应账户的“验证码”那一行
-		fmt.Printf("\033[%d;0H", startLine+3)
-		fmt.Printf("验证码: %s%s%s   \n", Green, code, Reset)
-
-		// 下一行更新剩余时间
-		fmt.Printf("剩余时间: %2d 秒 [%s]   \n", left, progressBar(total, float64(left)))
+		args = args[1:]
 	}
+	return args, color, nil
 }
 
-// Run 主程序
-func Run() {
-	addURI := flag.String("add", "", "添加账户 otpauth:// URI")
-	removeLabel := flag.String("remove", "", "删除账户，通过 label")
-	list := flag.Bool("list", false, "列出所有账户")
-	verifyCode := flag.String("verify", "", "验证输入验证码")
-	accountLabel := flag.String("account", "", "只显示或验证指定账户, 可逗号分隔")
-	addUser := flag.String("add-user", "", "添加账户用户名")
-	addKey := flag.String("add-key", "", "添加账户密钥")
-	addIssuer := flag.String("add-issuer", "", "服务提供者 / 平台名称")
-	addAlgo := flag.String("add-algo", "SHA1", "哈希算法: SHA1/SHA256/SHA512")
-	addPeriod := flag.Int64("add-period", 30, "时间步长 (秒)")
-	addDigits := flag.Int("add-digits", 6, "验证码位数")
-
-	flag.Parse()
+// runLegacy 旧版平铺参数入口
+// Deprecated: 请改用子命令，例如 go-totp add / go-totp verify
+func runLegacy(args []string) error {
+	fs := flag.NewFlagSet("go-totp", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	addURI := fs.String("add", "", "添加账户 otpauth:// URI")
+	removeLabel := fs.String("remove", "", "删除账户，通过 label")
+	list := fs.Bool("list", false, "列出所有账户")
+	verifyCode := fs.String("verify", "", "验证输入验证码")
+	accountLabel := fs.String("account", "", "只显示或验证指定账户, 可逗号分隔")
+	addUser := fs.String("add-user", "", "添加账户用户名")
+	addKey := fs.String("add-key", "", "添加账户密钥")
+	addIssuer := fs.String("add-issuer", "", "服务提供者 / 平台名称")
+	addAlgo := fs.String("add-algo", "SHA1", algoUsage("哈希算法: "))
+	addPeriod := fs.Int64("add-period", 30, "时间步长 (秒)")
+	addDigits := fs.Int("add-digits", 6, "验证码位数")
+	addName := fs.String("add-name", "", "添加账户时设置显示名称")
+	addIcon := fs.String("add-icon", "", "添加账户时设置图标（emoji 或短前缀）")
+	renameDisplay := fs.String("rename-display", "", "修改 -account 指定账户的显示名称")
+	diffFile := fs.String("diff", "", "与另一个账户文件比较差异")
+	jsonOutput := fs.Bool("json", false, "以 JSON 格式输出")
+	hotpMode := fs.Bool("hotp", false, "按计数器范围批量输出 HOTP 验证码（配合 -account）")
+	counterFrom := fs.Uint64("counter-from", 0, "HOTP 起始计数器")
+	counterTo := fs.Uint64("counter-to", 0, "HOTP 结束计数器（包含）")
+	once := fs.Bool("once", false, "输出一次当前验证码后退出")
+	group := fs.Bool("group", false, "分组显示验证码，例如 123 456")
+	groupSize := fs.Int("group-size", 0, "分组显示时每组位数（默认按位数自动选择）")
+	padZeros := fs.Bool("pad-zeros", false, "验证时为位数不足的验证码补齐前导零")
+	addClipboard := fs.Bool("add-clipboard", false, "从系统剪贴板读取 otpauth:// URI 并添加")
+
+	fs.Usage = func() { legacyUsage(fs) }
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
 
 	accounts, accsountFile, err := loadAccounts()
 	if err != nil {
-		log.Fatalf("读取账户失败: %v", err)
+		return fmt.Errorf("读取账户失败: %v", err)
+	}
+
+	// 从剪贴板读取 URI
+	if *addClipboard {
+		content, err := readClipboard()
+		if err != nil {
+			return err
+		}
+		if !isOtpauthURI(content) {
+			return fmt.Errorf("剪贴板内容不是 otpauth:// URI")
+		}
+		*addURI = content
 	}
 
 	// 添加账户
 	if *addURI != "" {
 		cfg, err := parseOtpauthURL(*addURI)
 		if err != nil {
-			log.Fatalf("解析 URI 失败: %v", err)
+			return fmt.Errorf("解析 URI 失败: %v", err)
 		}
-
-		// 检查重复
-		exists := false
-		for i, a := range accounts {
-			if a.Label == cfg.Label {
-				exists = true
-				accounts[i] = *cfg
-				break
-			}
+		cfg.DisplayName = *addName
+		cfg.Icon = *addIcon
+		if err := checkIcon(cfg.Icon); err != nil {
+			return err
 		}
-		if !exists {
-			accounts = append(accounts, *cfg)
-			fmt.Printf("✅ 添加成功: %s\n", cfg.Label)
-		} else {
-			fmt.Printf("⚠️ 已存在相同账户，已更新: %s\n", cfg.Label)
+		if err := addAccount(*cfg, accsountFile); err != nil {
+			return err
 		}
-
-		if err := saveAccounts(accounts, accsountFile); err != nil {
-			log.Fatalf("保存账户失败: %v", err)
-		}
-		return
+		return nil
 	}
 
 	// 删除账户
 	if *removeLabel != "" {
-		newAccs, ok := removeAccount(accounts, *removeLabel)
-		if !ok {
-			log.Fatalf("账户不存在: %s", *removeLabel)
-		}
-		accounts = newAccs
-		if err := saveAccounts(accounts, accsountFile); err != nil {
-			log.Fatalf("保存账户失败: %v", err)
+		if err := deleteAccount(*removeLabel, accsountFile); err != nil {
+			return err
 		}
-		fmt.Printf("✅ 删除成功: %s\n", *removeLabel)
-		return
+		return nil
 	}
 
 	// 列出账户
 	if *list {
-		fmt.Println("已保存账户列表:")
-		for _, a := range accounts {
-			fmt.Printf("- %s (%s) [%s]\n", a.Label, a.Issuer, a.Algorithm)
+		return printAccountList(accounts, listOptions{})
+	}
+
+	// 比较两个账户文件
+	if *diffFile != "" {
+		if err := diffWithFile(accounts, accsountFile, *diffFile, *jsonOutput); err != nil {
+			return err
 		}
-		return
+		return nil
 	}
 
 	// 过滤指定账户 (支持逗号)
-	var selectedAccounts []OTPConfig
-	if *accountLabel != "" {
-		labels := strings.Split(*accountLabel, ",")
-		labelMap := make(map[string]bool)
-		for _, l := range labels {
-			labelMap[strings.TrimSpace(l)] = true
-		}
-		for _, a := range accounts {
-			if labelMap[a.Label] {
-				selectedAccounts = append(selectedAccounts, a)
-				delete(labelMap, a.Label)
-			}
-		}
-		if len(labelMap) > 0 {
-			missing := []string{}
-			for l := range labelMap {
-				missing = append(missing, l)
-			}
-			log.Fatalf("❌ 未找到账户: %s", strings.Join(missing, ", "))
-		}
-	} else {
-		selectedAccounts = accounts
+	selectedAccounts, err := selectAccounts(accounts, *accountLabel)
+	if err != nil {
+		return err
 	}
 
 	// 通过用户名 + 密钥直接添加
 	if *addUser != "" && *addKey != "" {
-		cfg := &OTPConfig{
-			Label:     *addUser,
-			Secret:    *addKey,
-			Issuer:    *addIssuer,
-			Algorithm: totp.Algorithm(strings.ToUpper(*addAlgo)),
-			Period:    *addPeriod,
-			Digits:    *addDigits,
+		cfg := OTPConfig{
+			Label:       *addUser,
+			DisplayName: *addName,
+			Icon:        *addIcon,
+			Secret:      *addKey,
+			Issuer:      *addIssuer,
+			Algorithm:   totp.Algorithm(strings.ToUpper(*addAlgo)),
+			Period:      *addPeriod,
+			Digits:      *addDigits,
+		}
+		if err := checkAlgorithm(cfg.Algorithm); err != nil {
+			return err
+		}
+		if err := checkIcon(cfg.Icon); err != nil {
+			return err
 		}
+		if err := addAccount(cfg, accsountFile); err != nil {
+			return err
+		}
+		return nil
+	}
 
-		// 检查重复
-		exists := false
-		for i, a := range accounts {
-			if a.Label == cfg.Label {
-				exists = true
-				accounts[i] = *cfg
-				break
-			}
+	// 批量输出 HOTP
+	if *hotpMode {
+		if len(selectedAccounts) != 1 || *accountLabel == "" {
+			return fmt.Errorf("请通过 -account 指定一个账户")
 		}
-		if !exists {
-			accounts = append(accounts, *cfg)
-			fmt.Printf("✅ 添加成功: %s\n", cfg.Label)
-		} else {
-			fmt.Printf("⚠️ 已存在相同账户，已更新: %s\n", cfg.Label)
+		if err := printHOTPRange(selectedAccounts[0], *counterFrom, *counterTo); err != nil {
+			return fmt.Errorf("生成 HOTP 失败: %v", err)
 		}
+		return nil
+	}
 
-		if err := saveAccounts(accounts, accsountFile); err != nil {
-			log.Fatalf("保存账户失败: %v", err)
+	// 修改显示名称
+	if *renameDisplay != "" {
+		if len(selectedAccounts) != 1 || *accountLabel == "" {
+			return fmt.Errorf("请通过 -account 指定一个要修改的账户")
 		}
-		return
+		if err := setDisplayName(selectedAccounts[0].Label, *renameDisplay, accsountFile); err != nil {
+			return err
+		}
+		return nil
 	}
 
 	// 验证验证码
 	if *verifyCode != "" {
 		if len(selectedAccounts) == 0 {
-			log.Fatal("❌ 没有指定账户可验证")
-		}
-		valid := totp.ValidateTOTP(selectedAccounts[0].Secret, *verifyCode, selectedAccounts[0].Period, 1, selectedAccounts[0].Algorithm)
-		if valid {
-			fmt.Printf("%s✅ 验证成功 (%s)%s\n", Green, selectedAccounts[0].Label, Reset)
-		} else {
-			fmt.Printf("%s❌ 验证失败 (%s)%s\n", Red, selectedAccounts[0].Label, Reset)
+			return fmt.Errorf("没有指定账户可验证")
 		}
-		return
+		verifyAccount(selectedAccounts[0], *verifyCode, verifyOptions{padZeros: *padZeros})
+		return nil
 	}
 
 	// 动态显示
 	if len(selectedAccounts) == 0 {
-		fmt.Println("❌ 当前没有任何账户，请使用 --add 添加账户")
-		return
+		fmt.Fprintln(stdout, "❌ 当前没有任何账户，请使用 --add 添加账户")
+		return nil
+	}
+	dispOpts := displayOptions{
+		group:     *group || *groupSize > 0,
+		groupSize: *groupSize,
+		warn:      defaultWarnThreshold,
+		beep:      defaultBeepThreshold,
 	}
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	// 隐藏光标
-	fmt.Print("\033[?25l")
-	defer fmt.Print("\033[?25h") // 程序退出时恢复光标
 
-	displayAccounts(selectedAccounts, true) // 首次完整绘制
-	for {
-		select {
-		case <-ticker.C:
-			displayAccounts(selectedAccounts, false) // 仅局部更新
-		case <-sigChan:
-			fmt.Print("\033[?25h")      // 恢复光标显示
-			fmt.Print("\r\033[2K")      // 清空当前行
-			fmt.Println("\033[H\033[J") // 清空屏幕
-			fmt.Println("👋 已退出。")
-			return
+	// 只输出一次
+	if *once {
+		if err := printOnce(selectedAccounts, dispOpts, *jsonOutput); err != nil {
+			return fmt.Errorf("生成失败: %v", err)
 		}
+		return nil
 	}
+
+	watchAccounts(selectedAccounts, dispOpts, nil)
+	return nil
 }
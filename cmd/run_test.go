@@ -0,0 +1,188 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 03:20:41
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testSecret 测试用的 Base32 密钥
+const testSecret = "JBSWY3DPEHPK3PXP"
+
+// stubTerminal 记录终端控制调用，不输出转义序列
+type stubTerminal struct {
+	calls []string
+}
+
+func (t *stubTerminal) ClearScreen() { t.calls = append(t.calls, "clear") }
+func (t *stubTerminal) MoveTo(row, col int) {
+	t.calls = append(t.calls, fmt.Sprintf("move %d,%d", row, col))
+}
+func (t *stubTerminal) HideCursor() { t.calls = append(t.calls, "hide") }
+func (t *stubTerminal) ShowCursor() { t.calls = append(t.calls, "show") }
+func (t *stubTerminal) Beep()       { t.calls = append(t.calls, "beep") }
+
+// testHome 为测试准备独立的 HOME，并清除影响账户来源和输出的环境变量
+func testHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(accountsJSONEnv, "")
+	t.Setenv(readOnlyEnv, "")
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("CLICOLOR_FORCE", "")
+	return home
+}
+
+// runCLI 以 args 运行命令，返回写入的全部输出
+func runCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := RunWithOutput(args, &out, &stubTerminal{})
+	return out.String(), err
+}
+
+// mustRun 运行命令，失败时终止测试
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := runCLI(t, args...)
+	if err != nil {
+		t.Fatalf("%v: %v\n%s", args, err, out)
+	}
+	return out
+}
+
+// exitCodeOf 返回错误中的退出码，不是 *ExitError 时为 -1
+func exitCodeOf(err error) int {
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return exit.Code
+	}
+	return -1
+}
+
+func TestRunWithOutputRepeatable(t *testing.T) {
+	testHome(t)
+	mustRun(t, "add", "-label", "alice", "-secret", testSecret)
+	// 旧版参数注册在独立的 FlagSet 上，同一进程内多次调用不会因重复定义而 panic
+	for i := 0; i < 2; i++ {
+		out := mustRun(t, "-list")
+		if !strings.Contains(out, "alice") {
+			t.Fatalf("第 %d 次 -list 输出缺少账户: %q", i+1, out)
+		}
+	}
+}
+
+func TestRunWithOutputExitCodes(t *testing.T) {
+	testHome(t)
+	mustRun(t, "add", "-label", "alice", "-secret", testSecret)
+
+	out, err := runCLI(t, "remove")
+	if exitCodeOf(err) != 2 || !strings.Contains(out, "用法: go-totp remove") {
+		t.Errorf("remove 缺少参数: err=%v out=%q", err, out)
+	}
+	out, err = runCLI(t, "list", "-bogus")
+	if exitCodeOf(err) != 2 || !strings.Contains(out, "-bogus") {
+		t.Errorf("未知参数: err=%v out=%q", err, out)
+	}
+	if _, err := runCLI(t, "gen", "-h"); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("gen -h: err=%v, 期望 flag.ErrHelp", err)
+	}
+	if _, err := runCLI(t, "help", "gen"); err != nil {
+		t.Errorf("help gen: %v", err)
+	}
+	if _, err := runCLI(t, "verify", "-account", "alice", "00000"); exitCodeOf(err) != 1 {
+		t.Errorf("验证失败应返回退出码 1: %v", err)
+	}
+	if _, err := runCLI(t, "-verify", "1", "-account", "nobody"); err == nil || exitCodeOf(err) != -1 {
+		t.Errorf("旧版参数的错误应直接返回: %v", err)
+	}
+}
+
+func TestDisplayUsesTerminal(t *testing.T) {
+	var out bytes.Buffer
+	stub := &stubTerminal{}
+	SetOutput(&out, stub)
+	defer SetOutput(&bytes.Buffer{}, nil)
+	setColor(false)
+
+	accounts := []OTPConfig{{Label: "alice", Secret: testSecret, Algorithm: "SHA1", Period: 30, Digits: 6}}
+	layout := newGridLayout(1, 80)
+	codes := newStepCache()
+	displayAccounts(accounts, displayOptions{}, layout, codes, true)
+	displayAccounts(accounts, displayOptions{}, layout, codes, false)
+
+	if strings.Contains(out.String(), "\033[") {
+		t.Errorf("输出中不应包含终端转义序列: %q", out.String())
+	}
+	if len(stub.calls) == 0 || stub.calls[0] != "clear" {
+		t.Errorf("首次绘制应先清屏: %v", stub.calls)
+	}
+	if !strings.Contains(out.String(), "验证码: ") {
+		t.Errorf("缺少验证码行: %q", out.String())
+	}
+}
+
+func TestParseOtpauthURLCaseInsensitive(t *testing.T) {
+	for _, uri := range []string{
+		"otpauth://totp/alice?secret=" + testSecret,
+		"OTPAUTH://TOTP/alice?secret=" + testSecret,
+		"Otpauth://Totp/alice?secret=" + testSecret,
+		"otpauth://tOtP/alice?secret=" + testSecret,
+	} {
+		cfg, err := parseOtpauthURL(uri)
+		if err != nil {
+			t.Errorf("%s: %v", uri, err)
+			continue
+		}
+		if cfg.Label != "alice" || cfg.Secret != testSecret {
+			t.Errorf("%s: 解析结果不正确: %+v", uri, cfg)
+		}
+	}
+	for _, uri := range []string{
+		"OTPAUTH://HOTP/alice?secret=" + testSecret,
+		"http://totp/alice?secret=" + testSecret,
+	} {
+		if _, err := parseOtpauthURL(uri); err == nil {
+			t.Errorf("%s 应被拒绝", uri)
+		}
+	}
+}
+
+func TestRunWithOutputConcurrent(t *testing.T) {
+	testHome(t)
+	const n = 8
+	args := func(i int) []string {
+		return []string{"code", "-secret", testSecret, "-at", fmt.Sprint(1_000_000 + i*30)}
+	}
+	want := make([]string, n)
+	for i := range n {
+		want[i] = mustRun(t, args(i)...)
+	}
+
+	var wg sync.WaitGroup
+	outs := make([]bytes.Buffer, n)
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = RunWithOutput(args(i), &outs[i], &stubTerminal{})
+		}()
+	}
+	wg.Wait()
+	// 并发调用依次执行，各自的输出只写入自己的 Writer
+	for i := range n {
+		if errs[i] != nil || outs[i].String() != want[i] {
+			t.Errorf("第 %d 次调用: err=%v 输出 %q，期望 %q", i, errs[i], outs[i].String(), want[i])
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 19:47:15
+package cmd
+
+import (
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// scrubOnExit 全局参数 -scrub：退出时清零缓存的验证码和解码密钥
+var scrubOnExit bool
+
+// stepCacheKey 影响验证码的账户参数，账户被修改（如 watch 重新加载）后自然失效
+type stepCacheKey struct {
+	secret string
+	algo   totp.Algorithm
+	period int64
+	digits int
+}
+
+// stepCode 某个时间步的验证码，以字节切片保存以便过期时清零
+type stepCode struct {
+	step int64
+	code []byte
+}
+
+// stepCache 动态显示时缓存每个账户当前时间步的验证码
+// 验证码只在时间步变化时改变，每秒刷新只需重新计算倒计时，30 秒步长下 HMAC 计算减少约 30 倍
+type stepCache struct {
+	codes map[stepCacheKey]stepCode
+}
+
+// newStepCache 创建验证码缓存
+func newStepCache() *stepCache {
+	return &stepCache{codes: make(map[stepCacheKey]stepCode)}
+}
+
+// at 返回账户在 now 时的验证码、剩余秒数和步长；时间步未变化时不重新生成
+func (c *stepCache) at(cfg OTPConfig, now time.Time) (code string, left int, period int64, err error) {
+	period = cfg.Period
+	if period <= 0 {
+		period = totp.DefaultStep
+	}
+	step := now.Unix() / period
+	end := time.Unix((step+1)*period, 0)
+	left = int(end.Sub(now).Seconds())
+
+	key := stepCacheKey{cfg.Secret, cfg.Algorithm, period, cfg.Digits}
+	cached, ok := c.codes[key]
+	if ok && cached.step == step {
+		return string(cached.code), left, period, nil
+	}
+	res, err := totp.At(cfg.Secret, time.Unix(step*period, 0), cfg.options())
+	if err != nil {
+		return "", 0, 0, err
+	}
+	clear(cached.code) // 上一个时间步的验证码已失效
+	c.codes[key] = stepCode{step: step, code: []byte(res.Code)}
+	return res.Code, left, period, nil
+}
+
+// scrub 清零并清空缓存的验证码
+func (c *stepCache) scrub() {
+	for _, cached := range c.codes {
+		clear(cached.code)
+	}
+	clear(c.codes)
+}
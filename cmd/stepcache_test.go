@@ -0,0 +1,94 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 05:57:32
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+func TestStepCache(t *testing.T) {
+	cfg := OTPConfig{Label: "alice", Secret: testSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6}
+	start := time.Unix(1_700_000_010, 0) // 所在时间步为 [1700000010, 1700000040)
+	c := newStepCache()
+
+	want, err := totp.GenerateTOTPWithOptions(cfg.Secret, start, cfg.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, left, period, err := c.at(cfg, start)
+	if err != nil || code != want || left != 30 || period != 30 {
+		t.Fatalf("首次计算: code=%s left=%d period=%d err=%v，期望 %s", code, left, period, err, want)
+	}
+
+	// 将缓存替换为哨兵值：命中缓存时返回哨兵值，重新生成时返回真实验证码
+	poison := func(c *stepCache) {
+		for k, v := range c.codes {
+			c.codes[k] = stepCode{step: v.step, code: []byte("cached")}
+		}
+	}
+	poison(c)
+	if code, left, _, _ := c.at(cfg, start.Add(29*time.Second)); code != "cached" || left != 1 {
+		t.Errorf("同一时间步内应命中缓存: code=%s left=%d", code, left)
+	}
+
+	next := start.Add(30 * time.Second)
+	want, _ = totp.GenerateTOTPWithOptions(cfg.Secret, next, cfg.options())
+	if code, _, _, _ := c.at(cfg, next); code != want {
+		t.Errorf("下一个时间步应重新生成: %s，期望 %s", code, want)
+	}
+
+	changes := map[string]func(OTPConfig) OTPConfig{
+		"密钥": func(c OTPConfig) OTPConfig { c.Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"; return c },
+		"算法": func(c OTPConfig) OTPConfig { c.Algorithm = totp.SHA256; return c },
+		"步长": func(c OTPConfig) OTPConfig { c.Period = 60; return c },
+		"位数": func(c OTPConfig) OTPConfig { c.Digits = 8; return c },
+	}
+	for name, change := range changes {
+		poison(c)
+		changed := change(cfg)
+		want, err := totp.GenerateTOTPWithOptions(changed.Secret, next, changed.options())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code, _, _, _ := c.at(changed, next); code != want {
+			t.Errorf("修改%s后不应命中缓存: %s，期望 %s", name, code, want)
+		}
+	}
+}
+
+func TestStepCacheScrub(t *testing.T) {
+	cfg := OTPConfig{Label: "alice", Secret: testSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6}
+	c := newStepCache()
+	if _, _, _, err := c.at(cfg, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	var cached []byte
+	for _, v := range c.codes {
+		cached = v.code
+	}
+	c.scrub()
+	if len(c.codes) != 0 {
+		t.Errorf("scrub 后缓存应为空: %d", len(c.codes))
+	}
+	for _, b := range cached {
+		if b != 0 {
+			t.Fatalf("scrub 后验证码应被清零: %q", cached)
+		}
+	}
+}
+
+func BenchmarkStepCache(b *testing.B) {
+	cfg := OTPConfig{Label: "alice", Secret: testSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6}
+	c := newStepCache()
+	now := time.Unix(1_700_000_010, 0)
+	for i := 0; b.Loop(); i++ {
+		// 模拟每秒刷新一次
+		if _, _, _, err := c.at(cfg, now.Add(time.Duration(i)*time.Second)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 09:12:40
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// StoreVersion 当前程序支持的账户文件格式版本
+// 版本 1 为早期的裸 JSON 数组格式，版本 2 起使用带版本号的对象格式
+const StoreVersion = 2
+
+// accountStore 账户文件的磁盘格式
+type accountStore struct {
+	Version  int         `json:"version"`
+	Accounts []OTPConfig `json:"accounts"`
+}
+
+// readOnlyEnv 设置为 1 / true 时以只读模式运行
+const readOnlyEnv = "TOTP_READ_ONLY"
+
+// errReadOnly 只读模式下拒绝修改账户文件
+var errReadOnly = errors.New("只读模式下不允许修改账户文件（-read-only / " + readOnlyEnv + "）")
+
+// readOnly 是否以只读模式运行：list / verify / watch 等正常使用，任何写入账户文件的操作都会被拒绝
+var readOnly bool
+
+// readOnlyFromEnv 判断环境变量是否要求只读模式
+func readOnlyFromEnv() bool {
+	v, err := strconv.ParseBool(os.Getenv(readOnlyEnv))
+	return err == nil && v
+}
+
+// 去重函数
+func uniqueAccounts(accounts []OTPConfig) []OTPConfig {
+	seen := make(map[string]bool)
+	var result []OTPConfig
+	for _, a := range accounts {
+		if !seen[a.Label] {
+			seen[a.Label] = true
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// GetAccountFilePath 获取当前使用的账户文件路径
+// 指定了 -file 时为该文件；否则按配置档确定：默认配置档为 ~/.totp_accounts.json，
+// 其他配置档为 <用户配置目录>/go-totp/<名称>/accounts.json
+// 账户来自内存（-file - 或 TOTP_ACCOUNTS_JSON）时返回来源名称，不对应任何文件
+func GetAccountFilePath() (string, error) {
+	switch {
+	case memStore != nil:
+		return memStore.name, nil
+	case storeFile != "":
+		return storeFile, nil
+	}
+	return profileAccountFile(profile)
+}
+
+// decodeStore 解析账户文件内容
+// - 以 [ 开头的为旧版裸数组格式，读取后在下次保存时自动升级
+// - 版本号高于当前程序支持的版本时拒绝读取，避免旧程序误写新格式
+func decodeStore(data []byte) ([]OTPConfig, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return []OTPConfig{}, nil
+	}
+
+	// 旧版格式（版本 1）
+	if data[0] == '[' {
+		var accounts []OTPConfig
+		if err := json.Unmarshal(data, &accounts); err != nil {
+			return nil, err
+		}
+		return accounts, nil
+	}
+
+	var store accountStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, err
+	}
+	if store.Version > StoreVersion {
+		return nil, fmt.Errorf("账户文件版本为 %d，当前程序仅支持到版本 %d，请升级 go-totp", store.Version, StoreVersion)
+	}
+	return store.Accounts, nil
+}
+
+// encodeStore 按当前版本格式序列化账户
+func encodeStore(accounts []OTPConfig) ([]byte, error) {
+	if accounts == nil {
+		accounts = []OTPConfig{}
+	}
+	return json.MarshalIndent(accountStore{Version: StoreVersion, Accounts: accounts}, "", "  ")
+}
+
+// 本地账户操作
+func loadAccounts() ([]OTPConfig, string, error) {
+	if memStore != nil {
+		accounts, err := decodeStore(memStore.data)
+		return accounts, memStore.name, err
+	}
+	// 获取账户文件路径
+	accountFile, err := GetAccountFilePath()
+	if err != nil {
+		return nil, "", fmt.Errorf("❌ 获取账户文件路径失败: %v", err)
+	}
+	if _, err = os.Stat(accountFile); os.IsNotExist(err) {
+		if readOnly {
+			return nil, "", fmt.Errorf("账户文件不存在: %s（只读模式下不会自动创建）", accountFile)
+		}
+		// 文件不存在，创建空文件（非默认配置档需先创建目录）
+		emptyData, _ := encodeStore(nil)
+		if err = os.MkdirAll(filepath.Dir(accountFile), 0700); err != nil {
+			return nil, "", fmt.Errorf("创建账户目录失败: %v", err)
+		}
+		if err = os.WriteFile(accountFile, emptyData, 0600); err != nil {
+			return nil, "", fmt.Errorf("创建账户文件失败: %v", err)
+		}
+		return []OTPConfig{}, accountFile, nil
+	}
+
+	accounts, err := readAccountFile(accountFile)
+	if err != nil {
+		return nil, "", err
+	}
+	return accounts, accountFile, nil
+}
+
+// readAccountFile 读取并解析指定路径的账户文件（不存在时不会创建）
+func readAccountFile(path string) ([]OTPConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := decodeStore(data)
+	if err != nil {
+		return nil, err
+	}
+	return uniqueAccounts(accounts), nil
+}
+
+// saveAccounts 保存账户，始终以当前版本格式写入（旧版文件在此完成升级）
+// 先写临时文件再重命名，文件权限为 0600；开启 -backups 且账户内容有变化时在替换前将原文件轮换为历史版本（见 backup.go）
+func saveAccounts(accounts []OTPConfig, accountFile string) error {
+	if readOnly {
+		return errReadOnly
+	}
+	accounts = uniqueAccounts(accounts)
+	data, err := encodeStore(accounts)
+	if err != nil {
+		return err
+	}
+	accountFile = realPath(accountFile)
+	return writeFileAtomic(accountFile, data, 0600, func() error {
+		// 只记录使用时间或 HOTP 计数器的保存不轮换，否则日常的 gen / verify 会把真正的历史版本挤出去
+		if keep := backupRetention(); keep > 0 && storeChanged(accountFile, accounts) {
+			return rotateBackups(accountFile, keep)
+		}
+		return nil
+	})
+}
+
+// errNoChange updateAccounts 的回调返回此错误时不写入账户文件
+var errNoChange = errors.New("账户无变化")
+
+// updateAccounts 持有账户文件锁重新读取账户，交给 fn 修改后保存
+// 所有“读取-修改-写入”都应经过这里，否则可能用旧数据覆盖其他进程（如 hotp -next 递增的计数器）同时写入的修改
+// fn 返回 errNoChange 时不写入，并原样返回该错误
+func updateAccounts(accountFile string, fn func([]OTPConfig) ([]OTPConfig, error)) error {
+	return withStoreLock(accountFile, func() error {
+		accounts, err := readAccountFile(accountFile)
+		if errors.Is(err, os.ErrNotExist) {
+			accounts, err = []OTPConfig{}, nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取账户失败: %v", err)
+		}
+		if accounts, err = fn(accounts); err != nil {
+			return err
+		}
+		if err := saveAccounts(accounts, accountFile); err != nil {
+			return fmt.Errorf("保存账户失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// accountIndex 返回 label 对应账户的下标，不存在时为 -1
+func accountIndex(accounts []OTPConfig, label string) int {
+	for i, a := range accounts {
+		if a.Label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeAccount(accounts []OTPConfig, label string) ([]OTPConfig, bool) {
+	for i, a := range accounts {
+		if a.Label == label {
+			return append(accounts[:i], accounts[i+1:]...), true
+		}
+	}
+	return accounts, false
+}
+
+// upsertAccount 按 Label 添加或覆盖账户，返回是否为已存在账户
+func upsertAccount(accounts []OTPConfig, cfg OTPConfig) ([]OTPConfig, bool) {
+	for i, a := range accounts {
+		if a.Label == cfg.Label {
+			accounts[i] = cfg
+			return accounts, true
+		}
+	}
+	return append(accounts, cfg), false
+}
+
+// addAccount 添加（或更新同名）账户并保存
+func addAccount(cfg OTPConfig, accountFile string) error {
+	var exists bool
+	err := updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		accounts, exists = upsertAccount(accounts, cfg)
+		return accounts, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		fmt.Fprintf(stdout, "✅ 添加成功: %s\n", cfg.Label)
+	} else {
+		fmt.Fprintf(stdout, "⚠️ 已存在相同账户，已更新: %s\n", cfg.Label)
+	}
+	return nil
+}
+
+// deleteAccount 删除指定账户并保存
+func deleteAccount(label, accountFile string) error {
+	err := updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		accounts, ok := removeAccount(accounts, label)
+		if !ok {
+			return nil, fmt.Errorf("账户不存在: %s", label)
+		}
+		return accounts, nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ 删除成功: %s\n", label)
+	return nil
+}
+
+// setDisplayName 修改指定账户的显示名称并保存
+func setDisplayName(label, name, accountFile string) error {
+	err := updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		i := accountIndex(accounts, label)
+		if i < 0 {
+			return nil, fmt.Errorf("账户不存在: %s", label)
+		}
+		accounts[i].DisplayName = name
+		return accounts, nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ 显示名称已修改: %s -> %s\n", label, name)
+	return nil
+}
+
+// selectAccounts 按逗号分隔的 label 过滤账户，labels 为空时返回全部账户
+func selectAccounts(accounts []OTPConfig, labels string) ([]OTPConfig, error) {
+	if labels == "" {
+		return accounts, nil
+	}
+	labelMap := make(map[string]bool)
+	for _, l := range strings.Split(labels, ",") {
+		labelMap[strings.TrimSpace(l)] = true
+	}
+	var selected []OTPConfig
+	for _, a := range accounts {
+		if labelMap[a.Label] {
+			selected = append(selected, a)
+			delete(labelMap, a.Label)
+		}
+	}
+	if len(labelMap) > 0 {
+		missing := []string{}
+		for l := range labelMap {
+			missing = append(missing, l)
+		}
+		return nil, fmt.Errorf("未找到账户: %s", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// selectByIndex 按 list 中显示的序号（从 1 开始，逗号分隔）选择账户
+// 序号与账户文件中的保存顺序一致，增删账户前保持稳定
+func selectByIndex(accounts []OTPConfig, indexes string) ([]OTPConfig, error) {
+	var selected []OTPConfig
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(indexes, ",") {
+		part = strings.TrimSpace(part)
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("无效的账户序号: %q", part)
+		}
+		if n < 1 || n > len(accounts) {
+			return nil, fmt.Errorf("账户序号超出范围: %d（共 %d 个账户）", n, len(accounts))
+		}
+		if !seen[n] {
+			seen[n] = true
+			selected = append(selected, accounts[n-1])
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,80 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 11:24:51
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// isTerminal 判断文件是否为终端（字符设备）
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// readKeys 在后台逐字节读取标准输入，发送到返回的通道
+func readKeys() <-chan byte {
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			if n == 1 {
+				keys <- buf[0]
+			}
+		}
+	}()
+	return keys
+}
+
+// Terminal 动态显示所需的终端控制，嵌入或测试时可替换为自定义实现
+type Terminal interface {
+	ClearScreen()        // 清屏并将光标移到左上角
+	MoveTo(row, col int) // 将光标移到第 row 行第 col 列（均从 1 开始）
+	HideCursor()         // 隐藏光标
+	ShowCursor()         // 显示光标
+	Beep()               // 发出提示音
+}
+
+// ansiTerminal 基于 ANSI 转义序列的终端实现
+type ansiTerminal struct {
+	w io.Writer
+}
+
+func (t ansiTerminal) ClearScreen()        { fmt.Fprint(t.w, "\033[H\033[2J") }
+func (t ansiTerminal) MoveTo(row, col int) { fmt.Fprintf(t.w, "\033[%d;%dH", row, col) }
+func (t ansiTerminal) HideCursor()         { fmt.Fprint(t.w, "\033[?25l") }
+func (t ansiTerminal) ShowCursor()         { fmt.Fprint(t.w, "\033[?25h") }
+func (t ansiTerminal) Beep()               { fmt.Fprint(t.w, "\a") }
+
+// 所有输出的目标，默认为标准输出；errOut 为不影响结果输出的警告（如 -json 时）的目标，默认为标准错误
+var (
+	stdout io.Writer = os.Stdout
+	errOut io.Writer = os.Stderr
+	term   Terminal  = ansiTerminal{os.Stdout}
+)
+
+// SetOutput 设置输出目标；t 为 nil 时使用写入 w 的 ANSI 终端
+// 输出目标是包级状态，对之后的所有调用生效；RunWithOutput 每次调用都会重新设置
+func SetOutput(w io.Writer, t Terminal) {
+	if t == nil {
+		t = ansiTerminal{w}
+	}
+	stdout = w
+	term = t
+}
+
+// SetErrorOutput 设置警告信息的输出目标
+func SetErrorOutput(w io.Writer) {
+	errOut = w
+}
@@ -0,0 +1,56 @@
+//go:build !windows
+
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 11:24:51
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// stty 以当前标准输入为终端执行 stty 命令
+func stty(args ...string) (string, error) {
+	c := exec.Command("stty", args...)
+	c.Stdin = os.Stdin
+	out, err := c.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// enableRawInput 关闭标准输入的行缓冲和回显，使按键可以立即读取
+// Ctrl+C 等信号仍然有效；返回用于恢复终端状态的函数
+func enableRawInput() (func(), error) {
+	if !isTerminal(os.Stdin) {
+		return nil, fmt.Errorf("标准输入不是终端")
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("读取终端状态失败: %w", err)
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, fmt.Errorf("设置终端模式失败: %w", err)
+	}
+	return func() { _, _ = stty(saved) }, nil
+}
+
+// queryTerminalSize 通过 stty size 读取终端的行数和列数，失败时返回 0
+func queryTerminalSize() (rows, cols int) {
+	if !isTerminal(os.Stdin) {
+		return 0, 0
+	}
+	out, err := stty("size")
+	if err != nil {
+		return 0, 0
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0
+	}
+	rows, _ = strconv.Atoi(fields[0])
+	cols, _ = strconv.Atoi(fields[1])
+	return rows, cols
+}
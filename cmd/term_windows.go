@@ -0,0 +1,18 @@
+//go:build windows
+
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 11:24:51
+package cmd
+
+import "fmt"
+
+// enableRawInput Windows 下暂不支持逐键读取
+func enableRawInput() (func(), error) {
+	return nil, fmt.Errorf("当前平台不支持逐键读取")
+}
+
+// queryTerminalSize Windows 下暂不检测终端大小（可通过 LINES / COLUMNS 环境变量指定）
+func queryTerminalSize() (rows, cols int) {
+	return 0, 0
+}
@@ -0,0 +1,81 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 20:05:31
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// threshold 动态显示的剩余时间阈值，以秒或步长百分比表示
+type threshold struct {
+	value   float64
+	percent bool
+}
+
+// 默认阈值：剩余 25% 时进度条变红（50% 时变黄），剩余 5 秒时提示音
+var (
+	defaultWarnThreshold = threshold{value: 25, percent: true}
+	defaultBeepThreshold = threshold{value: 5}
+)
+
+// parseThreshold 解析阈值："10" / "10s" 为秒数，"25%" 为步长的百分比
+func parseThreshold(s string) (threshold, error) {
+	num := strings.TrimSpace(s)
+	t := threshold{}
+	switch {
+	case strings.HasSuffix(num, "%"):
+		t.percent = true
+		num = strings.TrimSuffix(num, "%")
+	case strings.HasSuffix(num, "s"):
+		num = strings.TrimSuffix(num, "s")
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v < 0 {
+		return threshold{}, fmt.Errorf("无效的阈值: %q（示例: 10、10s、25%%）", s)
+	}
+	if t.percent && v > 100 {
+		return threshold{}, fmt.Errorf("百分比阈值不能超过 100%%: %v%%", v)
+	}
+	t.value = v
+	return t, nil
+}
+
+// seconds 按步长换算为秒数
+func (t threshold) seconds(period int64) float64 {
+	if t.percent {
+		return float64(period) * t.value / 100
+	}
+	return t.value
+}
+
+// String 按输入格式输出，用于错误提示
+func (t threshold) String() string {
+	v := strconv.FormatFloat(t.value, 'f', -1, 64)
+	if t.percent {
+		return v + "%"
+	}
+	return v + "s"
+}
+
+// checkThresholds 校验每个账户的阈值满足 提示音 ≤ 变红 ≤ 步长
+func checkThresholds(accounts []OTPConfig, warn, beep threshold) error {
+	for _, cfg := range accounts {
+		period := cfg.Period
+		if period <= 0 {
+			period = totp.DefaultStep
+		}
+		w, b := warn.seconds(period), beep.seconds(period)
+		if w > float64(period) {
+			return fmt.Errorf("-warn-threshold %s 超过账户 %s 的步长 %d 秒", warn, cfg.Name(), period)
+		}
+		if b > w {
+			return fmt.Errorf("-beep-threshold %s 不能大于 -warn-threshold %s（账户 %s 步长 %d 秒）", beep, warn, cfg.Name(), period)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 22:03:48
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeFormat 输出中时间戳的格式：rfc3339、unix，或 Go 时间布局（如 "2006-01-02 15:04:05"）
+// 为空时各输出使用原有格式（JSON 为 RFC3339）
+type timeFormat string
+
+// 预定义的时间格式
+const (
+	timeRFC3339 timeFormat = "rfc3339"
+	timeUnix    timeFormat = "unix"
+)
+
+// timeFormatUsage -time-format 参数的帮助文字
+const timeFormatUsage = "时间戳格式: rfc3339（默认）、unix，或 Go 时间布局（如 \"2006-01-02 15:04:05\"）"
+
+// parseTimeFormat 解析 -time-format 参数
+func parseTimeFormat(s string) (timeFormat, error) {
+	switch f := timeFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", timeRFC3339, timeUnix:
+		return f, nil
+	}
+	// 不含任何布局元素的字符串格式化后原样输出，多半是拼写错误
+	if time.Unix(0, 0).UTC().Format(s) == s {
+		return "", fmt.Errorf("无效的时间格式: %q（可选: rfc3339、unix 或 Go 时间布局）", s)
+	}
+	return timeFormat(s), nil
+}
+
+// format 按格式输出时间，格式为空时使用 def 布局
+func (f timeFormat) format(t time.Time, def string) string {
+	switch f {
+	case "":
+		return t.Format(def)
+	case timeRFC3339:
+		return t.Format(time.RFC3339)
+	case timeUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.Format(string(f))
+}
+
+// stamp 按 timeFormat 序列化的时间：unix 为 JSON 数字，其余为字符串
+type stamp struct {
+	t time.Time
+	f timeFormat
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (s stamp) MarshalJSON() ([]byte, error) {
+	if s.f == timeUnix {
+		return strconv.AppendInt(nil, s.t.Unix(), 10), nil
+	}
+	return strconv.AppendQuote(nil, s.f.format(s.t, time.RFC3339)), nil
+}
@@ -0,0 +1,86 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 16:14:09
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// usageRecordInterval 动态显示期间记录使用时间的最小间隔，避免每次刷新都重写账户文件
+const usageRecordInterval = 10 * time.Minute
+
+// recordUse 在账户文件锁内更新账户的最近使用时间
+// 记录失败只输出警告，不影响生成或验证验证码；只读模式下不记录
+func recordUse(accounts []OTPConfig, t time.Time) {
+	if len(accounts) == 0 || readOnly {
+		return
+	}
+	accountFile, err := GetAccountFilePath()
+	if err == nil {
+		err = touchAccounts(accountFile, accounts, t)
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "⚠️ 记录使用时间失败: %v\n", err)
+	}
+}
+
+// touchAccounts 重新读取账户文件并设置指定账户的 LastUsedAt
+func touchAccounts(accountFile string, used []OTPConfig, t time.Time) error {
+	labels := make(map[string]bool, len(used))
+	for _, a := range used {
+		labels[a.Label] = true
+	}
+	return updateAccounts(accountFile, func(accounts []OTPConfig) ([]OTPConfig, error) {
+		for i := range accounts {
+			if labels[accounts[i].Label] {
+				accounts[i].LastUsedAt = t.UTC().Truncate(time.Second)
+			}
+		}
+		return accounts, nil
+	})
+}
+
+// usageRecorder 持续显示验证码期间定期记录使用时间
+type usageRecorder struct {
+	accounts []OTPConfig
+	last     time.Time
+}
+
+// tick 距上次记录超过 usageRecordInterval 时记录一次
+func (r *usageRecorder) tick(now time.Time) {
+	if !r.last.IsZero() && now.Sub(r.last) < usageRecordInterval {
+		return
+	}
+	r.flush(now)
+}
+
+// flush 立即记录（退出时调用）
+func (r *usageRecorder) flush(now time.Time) {
+	recordUse(r.accounts, now)
+	r.last = now
+}
+
+// parseAge 解析时长，在 time.ParseDuration 的基础上支持以天为单位（如 30d）
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("无效的时长: %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("无效的时长: %q（例如 30d、72h）", s)
+	}
+	return d, nil
+}
+
+// unusedSince 判断账户在 now 之前的 age 时间内是否未被使用（从未使用过的账户也算）
+func unusedSince(cfg OTPConfig, age time.Duration, now time.Time) bool {
+	return cfg.LastUsedAt.IsZero() || now.Sub(cfg.LastUsedAt) >= age
+}
@@ -0,0 +1,209 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 11:08:26
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// padCode 输入验证码短于 digits 位时在左侧补零
+// 用于兼容被表格等工具去掉前导零的验证码（如 012345 -> 12345）
+func padCode(code string, digits int) string {
+	if digits <= 0 {
+		digits = 6
+	}
+	if len(code) >= digits {
+		return code
+	}
+	return strings.Repeat("0", digits-len(code)) + code
+}
+
+// verifyOptions 验证相关选项
+type verifyOptions struct {
+	padZeros bool  // 为位数不足的验证码补齐前导零
+	period   int64 // 临时覆盖账户的步长（仅本次验证），0 表示使用账户配置
+
+	window    int           // 前后允许的时间步数
+	tolerance time.Duration // 以时间表示的容忍度，>0 时代替 window
+
+	algos []totp.Algorithm // 过渡期内同时接受的算法，为空时只使用账户配置的算法
+
+	at time.Time // 验证所用的时间，零值为本机当前时间
+}
+
+// resolve 返回实际使用的验证时间和前后允许的时间步数（-tolerance 按步长向上取整换算）
+func (o verifyOptions) resolve(period int64) (at time.Time, window int) {
+	at, window = o.at, o.window
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if o.tolerance > 0 && period > 0 {
+		// 与 totp.ValidateTOTPSeconds 相同，按步长向上取整
+		window = int((int64(o.tolerance/time.Second) + period - 1) / period)
+	}
+	return at, window
+}
+
+// verifyAccount 验证账户的验证码并输出结果
+func verifyAccount(cfg OTPConfig, code string, opts verifyOptions) bool {
+	if opts.padZeros {
+		code = padCode(code, cfg.Digits)
+	}
+	if opts.period > 0 {
+		cfg.Period = opts.period
+	}
+	at, window := opts.resolve(cfg.Period)
+	// 紧急静态码在有效期内与 TOTP 验证码同样接受
+	if cfg.staticCodeActive(at) && totp.CodesEqual(code, cfg.StaticCode) {
+		fmt.Fprintf(stdout, "%s✅ 验证成功 (%s)，使用的是紧急静态码（有效期至 %s）%s\n", Yellow, cfg.Label,
+			cfg.StaticValidUntil.Local().Format("2006-01-02 15:04"), Reset)
+		return true
+	}
+	check := func(algo totp.Algorithm) bool {
+		o := cfg.options()
+		o.Algorithm = algo
+		codes, err := totp.WindowCodes(cfg.Secret, at, o, window)
+		return err == nil && code != "" && slices.ContainsFunc(codes, func(c string) bool { return totp.CodesEqual(c, code) })
+	}
+	algos := opts.algos
+	if len(algos) == 0 {
+		algos = []totp.Algorithm{cfg.Algorithm}
+	}
+	var valid bool
+	var matched totp.Algorithm
+	for _, algo := range algos {
+		if valid = check(algo); valid {
+			matched = algo
+			break
+		}
+	}
+	if valid {
+		fmt.Fprintf(stdout, "%s✅ 验证成功 (%s)%s\n", Green, cfg.Label, Reset)
+		if opts.period > 0 {
+			fmt.Fprintf(stdout, "使用的步长: %ds\n", cfg.Period)
+		}
+		if len(opts.algos) > 0 {
+			fmt.Fprintf(stdout, "匹配的算法: %s\n", matched)
+		}
+	} else {
+		fmt.Fprintf(stdout, "%s❌ 验证失败 (%s)%s\n", Red, cfg.Label, Reset)
+		suggestDigits(cfg, code)
+	}
+	return valid
+}
+
+// printWindowReport 诊断用：列出按当前窗口设置会被接受的全部验证码（window=2 时为 5 个）
+// 用于核对窗口换算是否正确、评估大窗口扩大了多少可被猜中的范围；输出的是有效验证码，只应在排查时使用
+func printWindowReport(cfg OTPConfig, opts verifyOptions) error {
+	if opts.period > 0 {
+		cfg.Period = opts.period
+	}
+	at, window := opts.resolve(cfg.Period)
+	algos := opts.algos
+	if len(algos) == 0 {
+		algos = []totp.Algorithm{cfg.Algorithm}
+	}
+	o := cfg.options()
+	fmt.Fprintf(stdout, "账户 %s，时间 %s，步长 %d 秒，窗口 ±%d（共 %d 个验证码 × %d 个算法）\n",
+		cfg.Label, at.Format(time.RFC3339), cfg.Period, window, 2*window+1, len(algos))
+	for _, algo := range algos {
+		o.Algorithm = algo
+		codes, err := totp.WindowCodes(cfg.Secret, at, o, window)
+		if err != nil {
+			return err
+		}
+		if len(algos) > 1 {
+			fmt.Fprintf(stdout, "[%s]\n", algo)
+		}
+		for i, code := range codes {
+			offset := i - window
+			if code == "" {
+				continue // 早于 Unix 纪元
+			}
+			start, end := stepRange(at.Add(time.Duration(int64(offset)*cfg.Period)*time.Second), cfg.Period)
+			fmt.Fprintf(stdout, "  %+d  %s  %s ~ %s\n", offset, code, start.Format(time.TimeOnly), end.Format(time.TimeOnly))
+		}
+	}
+	if cfg.staticCodeActive(at) {
+		fmt.Fprintf(stdout, "%s另外接受紧急静态码（有效期至 %s）%s\n", Yellow, cfg.StaticValidUntil.Local().Format("2006-01-02 15:04"), Reset)
+	}
+	return nil
+}
+
+// suggestDigits 验证码长度与账户位数不一致时给出排查建议（只提示，不修改账户）
+func suggestDigits(cfg OTPConfig, code string) {
+	digits := cfg.Digits
+	if digits == 0 {
+		digits = 6
+	}
+	if len(code) == digits || strings.Trim(code, "0123456789") != "" {
+		return
+	}
+	fmt.Fprintf(stdout, "%s⚠️ 输入的验证码为 %d 位，但账户 %s 设置为 %d 位：可能选错了账户，或账户的位数设置有误%s\n",
+		Yellow, len(code), cfg.Label, digits, Reset)
+	if len(code) < digits {
+		fmt.Fprintln(stdout, "如果验证码的前导零被去掉了，可加 -pad-zeros 重试")
+	}
+	fmt.Fprintf(stdout, "可运行 go-totp detect-upgrade -account %s <验证码> 检查算法与位数，确认后再更新账户\n", cfg.Label)
+}
+
+// confirmAttempts 确认录入时允许输入验证码的次数
+const confirmAttempts = 3
+
+// confirmEnrollment 提示用户输入验证器 App 显示的验证码，通过后才允许保存账户
+func confirmEnrollment(cfg OTPConfig, in io.Reader) error {
+	reader := bufio.NewReader(in)
+	for i := 1; i <= confirmAttempts; i++ {
+		fmt.Fprintf(stdout, "请输入验证器 App 中 %s 显示的验证码 (%d/%d): ", cfg.Name(), i, confirmAttempts)
+		line, err := reader.ReadString('\n')
+		code := strings.TrimSpace(line)
+		if code != "" && totp.ConfirmEnrollment(cfg.Secret, code, cfg.options()) {
+			fmt.Fprintf(stdout, "%s✅ 验证通过%s\n", Green, Reset)
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintln(stdout)
+			return fmt.Errorf("未完成确认，账户未保存")
+		}
+		fmt.Fprintf(stdout, "%s❌ 验证码不正确%s\n", Red, Reset)
+	}
+	return fmt.Errorf("验证码多次不正确，账户未保存，请检查 App 中的账户设置后重试")
+}
+
+// checkVerifyCode 校验 -verify-code 指定的验证码，用于脚本化录入（不交互）
+func checkVerifyCode(cfg OTPConfig, code string) error {
+	if !totp.ConfirmEnrollment(cfg.Secret, strings.TrimSpace(code), cfg.options()) {
+		return fmt.Errorf("验证码不正确，账户未保存，请检查密钥与验证器 App 中的账户设置")
+	}
+	fmt.Fprintf(stdout, "%s✅ 验证通过%s\n", Green, Reset)
+	return nil
+}
+
+// parseAlgorithms 解析逗号分隔的算法列表，例如 "SHA1,SHA256"
+func parseAlgorithms(s string) ([]totp.Algorithm, error) {
+	var algos []totp.Algorithm
+	for _, part := range strings.Split(s, ",") {
+		algo := totp.Algorithm(strings.ToUpper(strings.TrimSpace(part)))
+		if algo == "" {
+			continue
+		}
+		if err := checkAlgorithm(algo); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(algos, algo) {
+			algos = append(algos, algo)
+		}
+	}
+	if len(algos) == 0 {
+		return nil, fmt.Errorf("算法列表不能为空")
+	}
+	return algos, nil
+}
@@ -0,0 +1,143 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 04:18:52
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/wsk20/go-totp/pkg/totp"
+)
+
+// captureOutput 将输出重定向到缓冲区，测试结束后恢复为标准输出
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	SetOutput(&out, &stubTerminal{})
+	setColor(false)
+	t.Cleanup(func() { SetOutput(os.Stdout, nil) })
+	return &out
+}
+
+// leadingZeroTime 返回验证码以 0 开头的某个时间步的起始时间及该验证码
+func leadingZeroTime(t *testing.T, cfg OTPConfig) (time.Time, string) {
+	t.Helper()
+	for step := int64(50_000_000); step < 50_001_000; step++ {
+		at := time.Unix(step*30, 0)
+		code, err := totp.GenerateTOTPWithOptions(cfg.Secret, at, cfg.options())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code[0] == '0' {
+			return at, code
+		}
+	}
+	t.Fatal("未找到以 0 开头的验证码")
+	return time.Time{}, ""
+}
+
+func TestPadCode(t *testing.T) {
+	tests := []struct {
+		code   string
+		digits int
+		want   string
+	}{
+		{"12345", 6, "012345"},
+		{"345", 6, "000345"},
+		{"123456", 6, "123456"},
+		{"1234567", 6, "1234567"},
+		{"12345", 0, "012345"},
+		{"1234567", 8, "01234567"},
+	}
+	for _, tt := range tests {
+		if got := padCode(tt.code, tt.digits); got != tt.want {
+			t.Errorf("padCode(%q, %d) = %q，期望 %q", tt.code, tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyPadZeros(t *testing.T) {
+	captureOutput(t)
+	cfg := OTPConfig{Label: "alice", Secret: testSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6}
+	at, code := leadingZeroTime(t, cfg)
+	stripped := code[1:]
+
+	if verifyAccount(cfg, stripped, verifyOptions{at: at}) {
+		t.Errorf("未开启 -pad-zeros 时 5 位验证码 %s 不应通过", stripped)
+	}
+	if !verifyAccount(cfg, stripped, verifyOptions{at: at, padZeros: true}) {
+		t.Errorf("-pad-zeros 应将 %s 补齐为 %s 并通过", stripped, code)
+	}
+	if verifyAccount(cfg, "99999", verifyOptions{at: at, padZeros: true}) && code != "099999" {
+		t.Error("补零后仍不匹配的验证码不应通过")
+	}
+}
+
+func TestVerifyStaticCodeExpiry(t *testing.T) {
+	captureOutput(t)
+	until := time.Unix(1_700_000_000, 0)
+	cfg := OTPConfig{Label: "alice", Secret: testSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6,
+		StaticCode: "73910482", StaticValidUntil: until}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"有效期内", until.Add(-time.Hour), true},
+		{"到期前一刻", until.Add(-time.Nanosecond), true},
+		{"到期时刻", until, false},
+		{"到期之后", until.Add(time.Second), false},
+	}
+	for _, tt := range tests {
+		if got := verifyAccount(cfg, cfg.StaticCode, verifyOptions{at: tt.at}); got != tt.want {
+			t.Errorf("%s: 静态码验证结果 %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 到期后仍接受 TOTP 验证码
+	at := until.Add(time.Minute)
+	code, err := totp.GenerateTOTPWithOptions(cfg.Secret, at, cfg.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verifyAccount(cfg, code, verifyOptions{at: at}) {
+		t.Error("静态码到期后 TOTP 验证码应照常通过")
+	}
+
+	// 未设置有效期的静态码不生效
+	cfg.StaticValidUntil = time.Time{}
+	if verifyAccount(cfg, cfg.StaticCode, verifyOptions{at: until.Add(-time.Hour)}) {
+		t.Error("未设置有效期的静态码不应通过")
+	}
+}
+
+func TestVerifyStaticCodeMismatch(t *testing.T) {
+	captureOutput(t)
+	until := time.Unix(1_700_000_000, 0)
+	at := until.Add(-time.Hour)
+	cfg := OTPConfig{Label: "alice", Secret: testSecret, Algorithm: totp.SHA1, Period: 30, Digits: 6,
+		StaticCode: "73910482", StaticValidUntil: until}
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"长度相同但不一致", "73910483"},
+		{"长度相同但首位不一致", "83910482"},
+		{"前缀", "739104"},
+		{"更长", "739104820"},
+		{"空验证码", ""},
+	}
+	for _, tt := range tests {
+		if verifyAccount(cfg, tt.code, verifyOptions{at: at}) {
+			t.Errorf("%s: %q 不应通过静态码验证", tt.name, tt.code)
+		}
+	}
+	if !verifyAccount(cfg, cfg.StaticCode, verifyOptions{at: at}) {
+		t.Error("一致的静态码应通过")
+	}
+}
@@ -0,0 +1,99 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-16 22:58:14
+package cmd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// wipeDelay 使用 -yes 跳过确认时，开始擦除前的等待时间，留出按 Ctrl+C 取消的机会
+const wipeDelay = 3 * time.Second
+
+// wipeConfirmWord 不使用 -yes 时需要输入的确认词
+const wipeConfirmWord = "wipe"
+
+// wipeTargets 需要擦除的文件：账户文件、其完整性校验文件和全部历史版本
+// 本机配置和锁文件不包含账户或密钥，不在其中
+func wipeTargets(accountFile string) []string {
+	targets := []string{accountFile, sealPath(accountFile)}
+	backups, _ := listBackups(accountFile) // 目录无法读取时账户文件本身也会擦除失败，由调用方报告
+	for _, b := range backups {
+		targets = append(targets, b.path)
+	}
+	return targets
+}
+
+// overwriteFile 用随机字节覆盖文件全部内容并落盘，然后删除
+// 只是尽力而为：日志型文件系统、写时复制文件系统和 SSD 的磨损均衡都可能在别处保留旧数据
+func overwriteFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err == nil {
+		_, err = io.CopyN(f, rand.Reader, info.Size())
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("覆盖 %s 失败: %w", path, err)
+	}
+	return os.Remove(path)
+}
+
+// wipeStore 确认后覆盖并删除账户文件，返回实际擦除的文件
+// assumeYes 为 false 时要求输入确认词；为 true 时等待 delay 后直接开始
+func wipeStore(accountFile string, in io.Reader, assumeYes bool, delay time.Duration) ([]string, error) {
+	var targets []string
+	for _, path := range wipeTargets(accountFile) {
+		if _, err := os.Stat(path); err == nil {
+			targets = append(targets, path)
+		}
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(stdout, "没有需要擦除的文件")
+		return nil, nil
+	}
+	fmt.Fprintf(stdout, "%s⚠️ 将不可恢复地擦除以下文件中的全部账户和密钥:%s\n", Red, Reset)
+	for _, path := range targets {
+		fmt.Fprintf(stdout, "  %s\n", path)
+	}
+	if assumeYes {
+		fmt.Fprintf(stdout, "%s 后开始擦除，按 Ctrl+C 取消...\n", delay)
+		time.Sleep(delay)
+	} else {
+		fmt.Fprintf(stdout, "输入 %s 确认: ", wipeConfirmWord)
+		line, _ := bufio.NewReader(in).ReadString('\n')
+		if strings.TrimSpace(line) != wipeConfirmWord {
+			fmt.Fprintln(stdout, "已取消")
+			return nil, nil
+		}
+	}
+
+	var wiped []string
+	err := withStoreLock(accountFile, func() error {
+		for _, path := range targets {
+			if err := overwriteFile(path); err != nil {
+				return err
+			}
+			wiped = append(wiped, path)
+		}
+		return nil
+	})
+	for _, path := range wiped {
+		fmt.Fprintf(stdout, "🗑️ 已擦除: %s\n", path)
+	}
+	return wiped, err
+}
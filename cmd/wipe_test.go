@@ -0,0 +1,81 @@
+// Package cmd
+// Author: wsk20
+// Created on: 2026-10-17 06:47:05
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWipeStore(t *testing.T) {
+	testHome(t)
+	captureOutput(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "accounts.json")
+	mustRun(t, "-file", path, "-backups", "2", "add", "-label", "alice", "-secret", testSecret)
+	mustRun(t, "-file", path, "-backups", "2", "add", "-label", "bob", "-secret", testSecret)
+	mustRun(t, "-file", path, "-backups", "2", "add", "-label", "carol", "-secret", testSecret)
+	if err := os.WriteFile(sealPath(path), []byte("seal"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	targets := []string{path, sealPath(path), backupPath(path, 1), backupPath(path, 2)}
+	for _, p := range targets {
+		if _, err := os.Stat(p); err != nil {
+			t.Fatalf("测试前应存在 %s: %v", p, err)
+		}
+	}
+
+	// 硬链接指向同一份数据，删除后仍可检查内容是否已被覆盖
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Link(path, link); err != nil {
+		t.Skipf("不支持硬链接: %v", err)
+	}
+
+	// 确认词不正确时不擦除
+	if wiped, err := wipeStore(path, strings.NewReader("yes\n"), false, 0); err != nil || len(wiped) != 0 {
+		t.Fatalf("确认词不正确时不应擦除: %v %v", wiped, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("取消后账户文件应保留: %v", err)
+	}
+
+	wiped, err := wipeStore(path, strings.NewReader(wipeConfirmWord+"\n"), false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wiped) != len(targets) {
+		t.Errorf("擦除了 %v，期望 %v", wiped, targets)
+	}
+	for _, p := range targets {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s 应已删除: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("无关文件不应被删除: %v", err)
+	}
+	data, err := os.ReadFile(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != len(original) || bytes.Equal(data, original) || bytes.Contains(data, []byte("alice")) {
+		t.Error("删除前应先用随机字节覆盖原内容")
+	}
+
+	// 再次擦除时没有需要处理的文件
+	if wiped, err := wipeStore(path, nil, true, 0); err != nil || len(wiped) != 0 {
+		t.Errorf("没有文件时应直接返回: %v %v", wiped, err)
+	}
+}
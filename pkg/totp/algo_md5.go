@@ -0,0 +1,18 @@
+//go:build totp_legacy
+
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 14:02:37
+package totp
+
+import "crypto/md5"
+
+// MD5 HMAC-MD5 算法，仅用于兼容极少数老旧令牌
+//
+// Deprecated: MD5 已不再安全，新账户请勿使用。
+// 该算法只在使用 -tags totp_legacy 构建时可用，默认构建中不存在。
+const MD5 Algorithm = "MD5"
+
+func init() {
+	hashFuncs[MD5] = md5.New
+}
@@ -0,0 +1,35 @@
+//go:build totp_legacy
+
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 05:11:26
+package totp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMD5Legacy(t *testing.T) {
+	if !IsSupported(MD5) || !slices.Contains(SupportedAlgorithms(), MD5) {
+		t.Fatal("使用 totp_legacy 构建时应注册 MD5")
+	}
+	// 期望值由独立的 HMAC-MD5 实现按 RFC 6238 的流程计算得出
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "78532013"},
+		{1111111109, "13672061"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateTOTPWithOptions(rfcSecret(SHA1), time.Unix(tt.unix, 0), Options{Algorithm: MD5, Digits: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.code {
+			t.Errorf("MD5 @%d = %s，期望 %s", tt.unix, got, tt.code)
+		}
+	}
+}
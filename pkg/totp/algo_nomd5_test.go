@@ -0,0 +1,14 @@
+//go:build !totp_legacy
+
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 05:12:03
+package totp
+
+import "testing"
+
+func TestMD5NotInDefaultBuild(t *testing.T) {
+	if IsSupported("MD5") {
+		t.Error("默认构建不应包含 MD5")
+	}
+}
@@ -0,0 +1,80 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 20:21:09
+package totp
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// builtinAlgorithms 内置算法，SupportedAlgorithms 中始终排在最前
+var builtinAlgorithms = []Algorithm{SHA1, SHA256, SHA512}
+
+var (
+	hashMu sync.RWMutex
+
+	// hashFuncs 算法注册表：算法名 -> 哈希函数
+	// 默认构建只包含 SHA1/SHA256/SHA512，遗留算法由构建标签在 init 中注册（见 algo_md5.go）
+	hashFuncs = map[Algorithm]func() hash.Hash{
+		SHA1:   sha1.New,
+		SHA256: sha256.New,
+		SHA512: sha512.New,
+	}
+)
+
+// RegisterAlgorithm 注册自定义算法，名称统一转为大写
+// 不允许覆盖已注册的算法，避免同名算法在不同程序中得到不同的验证码
+func RegisterAlgorithm(name Algorithm, h func() hash.Hash) error {
+	name = Algorithm(strings.ToUpper(strings.TrimSpace(string(name))))
+	if name == "" || h == nil {
+		return fmt.Errorf("[TOTP] 注册算法失败: 名称与哈希函数不能为空")
+	}
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	if _, ok := hashFuncs[name]; ok {
+		return fmt.Errorf("[TOTP] 注册算法失败: %s 已存在", name)
+	}
+	hashFuncs[name] = h
+	return nil
+}
+
+// SupportedAlgorithms 返回已注册的全部算法：内置的 SHA1/SHA256/SHA512 在前，其余按名称排序
+func SupportedAlgorithms() []Algorithm {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	algos := slices.Clone(builtinAlgorithms)
+	var extra []Algorithm
+	for name := range hashFuncs {
+		if !slices.Contains(builtinAlgorithms, name) {
+			extra = append(extra, name)
+		}
+	}
+	slices.Sort(extra)
+	return append(algos, extra...)
+}
+
+// IsSupported 判断算法是否已注册
+func IsSupported(algo Algorithm) bool {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	_, ok := hashFuncs[algo]
+	return ok
+}
+
+// getHMACFunc 返回对应算法的哈希函数，用于生成 HMAC
+// 未注册的算法默认使用 SHA1
+func getHMACFunc(algo Algorithm) func() hash.Hash {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	if h, ok := hashFuncs[algo]; ok {
+		return h
+	}
+	return sha1.New
+}
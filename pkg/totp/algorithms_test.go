@@ -0,0 +1,49 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 06:11:52
+package totp
+
+import (
+	"crypto/sha256"
+	"slices"
+	"testing"
+)
+
+func TestSupportedAlgorithms(t *testing.T) {
+	algos := SupportedAlgorithms()
+	if len(algos) < 3 || !slices.Equal(algos[:3], []Algorithm{SHA1, SHA256, SHA512}) {
+		t.Fatalf("内置算法应排在最前: %v", algos)
+	}
+	for _, algo := range algos[:3] {
+		if !IsSupported(algo) {
+			t.Errorf("IsSupported(%s) = false", algo)
+		}
+	}
+	if IsSupported("SHA3") {
+		t.Error("未注册的算法不应受支持")
+	}
+
+	// 返回副本，调用方修改不影响注册表
+	algos[0] = "X"
+	if SupportedAlgorithms()[0] != SHA1 {
+		t.Error("修改返回值不应影响注册表")
+	}
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	if err := RegisterAlgorithm(" sha224-test ", sha256.New224); err != nil {
+		t.Fatal(err)
+	}
+	if !IsSupported("SHA224-TEST") || !slices.Contains(SupportedAlgorithms(), Algorithm("SHA224-TEST")) {
+		t.Error("注册的算法应统一转为大写并出现在 SupportedAlgorithms 中")
+	}
+	if err := RegisterAlgorithm("SHA224-TEST", sha256.New); err == nil {
+		t.Error("不应允许重复注册同名算法")
+	}
+	if err := RegisterAlgorithm("SHA1", sha256.New); err == nil {
+		t.Error("不应允许覆盖内置算法")
+	}
+	if err := RegisterAlgorithm("", sha256.New); err == nil {
+		t.Error("算法名为空时应返回错误")
+	}
+}
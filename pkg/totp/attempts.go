@@ -0,0 +1,122 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 02:14:37
+package totp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTooManyAttempts 账户连续验证失败次数已达 Validator.MaxAttempts，在解除锁定前不再验证
+var ErrTooManyAttempts = errors.New("[TOTP] 验证失败次数过多，账户已锁定")
+
+// attemptState 某账户的连续失败次数与最近一次失败时间
+type attemptState struct {
+	failures int
+	last     time.Time
+}
+
+// expired 判断失败计数是否已超过 LockoutDuration 而应清零；LockoutDuration 为 0 时永不过期
+func (s *attemptState) expired(lockout time.Duration, now time.Time) bool {
+	return lockout > 0 && now.Sub(s.last) >= lockout
+}
+
+// countsAsAttempt 判断一次未通过的验证是否计入失败次数
+// 密钥无法解码等配置错误不是用户的尝试，不计入
+func countsAsAttempt(err error) bool {
+	return err == nil || errors.Is(err, ErrCodeReplayed) || errors.Is(err, ErrCodeTooOld) || errors.Is(err, ErrDigitsMismatch)
+}
+
+// limitAttempts 在 MaxAttempts 开启时包装一次验证：先在锁内预占一次尝试（已锁定时直接返回 ErrTooManyAttempts），
+// 再执行 validate；成功时清零失败次数，不计入的失败（如密钥无法解码）退还预占，其余失败保留
+// 检查与计数在同一临界区内完成，并发的错误猜测不会同时通过检查而超出 MaxAttempts
+func (v *Validator) limitAttempts(accountKey string, now time.Time, validate func() (ValidationResult, error)) (ValidationResult, error) {
+	if v.MaxAttempts <= 0 {
+		res, err := validate()
+		res.AttemptsRemaining = -1
+		return res, err
+	}
+	left, ok := v.reserveAttempt(accountKey, now)
+	if !ok {
+		return ValidationResult{}, fmt.Errorf("%w: 连续失败 %d 次", ErrTooManyAttempts, v.MaxAttempts)
+	}
+	res, err := validate()
+	switch {
+	case res.Valid:
+		v.ResetAttempts(accountKey)
+		res.AttemptsRemaining = v.MaxAttempts
+	case countsAsAttempt(err):
+		res.AttemptsRemaining = left
+	default:
+		res.AttemptsRemaining = v.releaseAttempt(accountKey)
+	}
+	return res, err
+}
+
+// reserveAttempt 预占一次尝试：未锁定时失败次数加一并返回此后的剩余次数，已锁定时返回 false
+func (v *Validator) reserveAttempt(accountKey string, now time.Time) (int, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.attempts == nil {
+		v.attempts = make(map[string]*attemptState)
+	}
+	s, ok := v.attempts[accountKey]
+	if !ok || s.expired(v.LockoutDuration, now) {
+		s = &attemptState{}
+		v.attempts[accountKey] = s
+	}
+	if s.failures >= v.MaxAttempts {
+		return 0, false
+	}
+	s.failures++
+	s.last = now
+	return v.MaxAttempts - s.failures, true
+}
+
+// releaseAttempt 退还一次预占的尝试，返回剩余次数
+func (v *Validator) releaseAttempt(accountKey string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.attempts[accountKey]
+	if !ok {
+		return v.MaxAttempts
+	}
+	if s.failures--; s.failures <= 0 {
+		delete(v.attempts, accountKey)
+		return v.MaxAttempts
+	}
+	return v.MaxAttempts - s.failures
+}
+
+// attemptsLeft 返回 accountKey 在 now 时刻的剩余尝试次数，顺带清除已过期的失败计数
+func (v *Validator) attemptsLeft(accountKey string, now time.Time) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.attempts[accountKey]
+	if !ok {
+		return v.MaxAttempts
+	}
+	if s.expired(v.LockoutDuration, now) {
+		delete(v.attempts, accountKey)
+		return v.MaxAttempts
+	}
+	return max(0, v.MaxAttempts-s.failures)
+}
+
+// AttemptsRemaining 返回 accountKey 被锁定前还可尝试的次数，供界面提示“还剩 N 次机会”
+// 为 0 表示已锁定；未开启 MaxAttempts 时返回 -1（不限次数）
+func (v *Validator) AttemptsRemaining(accountKey string) int {
+	if v.MaxAttempts <= 0 {
+		return -1
+	}
+	return v.attemptsLeft(accountKey, time.Now())
+}
+
+// ResetAttempts 清零 accountKey 的失败次数并解除锁定（例如管理员人工解锁后）
+func (v *Validator) ResetAttempts(accountKey string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.attempts, accountKey)
+}
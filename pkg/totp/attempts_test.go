@@ -0,0 +1,126 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 03:58:12
+package totp
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+const attemptSecret = "JBSWY3DPEHPK3PXP"
+
+var attemptTime = time.Unix(1_700_000_000, 0)
+
+// attemptCodes 返回 attemptTime 时的正确验证码和一个一定错误的验证码
+func attemptCodes(t *testing.T) (good, bad string) {
+	t.Helper()
+	good, err := GenerateTOTPWithTime(attemptSecret, 30, attemptTime, SHA1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := []byte(good)
+	b[5] = '0' + (b[5]-'0'+1)%10
+	return good, string(b)
+}
+
+func TestAttemptsRemainingDecrementsAndLocks(t *testing.T) {
+	_, bad := attemptCodes(t)
+	v := &Validator{Window: 1, MaxAttempts: 3}
+	for want := 2; want >= 0; want-- {
+		res, err := v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime)
+		if err != nil || res.Valid {
+			t.Fatalf("错误验证码: res=%+v err=%v", res, err)
+		}
+		if res.AttemptsRemaining != want {
+			t.Errorf("AttemptsRemaining = %d，期望 %d", res.AttemptsRemaining, want)
+		}
+	}
+	if _, err := v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("超过次数后应返回 ErrTooManyAttempts: %v", err)
+	}
+	if n := v.AttemptsRemaining("alice"); n != 0 {
+		t.Errorf("锁定后 AttemptsRemaining = %d", n)
+	}
+	if n := v.AttemptsRemaining("bob"); n != 3 {
+		t.Errorf("其他账户不受影响: %d", n)
+	}
+}
+
+func TestAttemptsResetOnSuccess(t *testing.T) {
+	good, bad := attemptCodes(t)
+	v := &Validator{Window: 1, MaxAttempts: 3}
+	v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime)
+	v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime)
+	res, err := v.ValidateAt("alice", attemptSecret, good, Options{}, attemptTime)
+	if err != nil || !res.Valid {
+		t.Fatalf("正确验证码: res=%+v err=%v", res, err)
+	}
+	if res.AttemptsRemaining != 3 || v.AttemptsRemaining("alice") != 3 {
+		t.Errorf("成功后应恢复为 3 次: %d / %d", res.AttemptsRemaining, v.AttemptsRemaining("alice"))
+	}
+}
+
+func TestAttemptsResetAndExpiry(t *testing.T) {
+	_, bad := attemptCodes(t)
+	v := &Validator{Window: 1, MaxAttempts: 1, LockoutDuration: time.Minute}
+	v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime)
+	if _, err := v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime.Add(59*time.Second)); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("锁定期内: %v", err)
+	}
+	if res, err := v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime.Add(time.Minute)); err != nil || res.AttemptsRemaining != 0 {
+		t.Errorf("锁定到期后应重新计数: res=%+v err=%v", res, err)
+	}
+	v.ResetAttempts("alice")
+	if n := v.AttemptsRemaining("alice"); n != 1 {
+		t.Errorf("ResetAttempts 后 AttemptsRemaining = %d", n)
+	}
+}
+
+func TestAttemptsNotCountedForBadSecret(t *testing.T) {
+	v := &Validator{Window: 1, MaxAttempts: 2}
+	res, err := v.ValidateAt("alice", "!!", "123456", Options{}, attemptTime)
+	if err == nil || errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("应返回解码错误: %v", err)
+	}
+	if res.AttemptsRemaining != 2 {
+		t.Errorf("密钥无法解码不应计入失败: %d", res.AttemptsRemaining)
+	}
+}
+
+func TestAttemptsDisabled(t *testing.T) {
+	_, bad := attemptCodes(t)
+	v := &Validator{Window: 1}
+	res, _ := v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime)
+	if res.AttemptsRemaining != -1 || v.AttemptsRemaining("alice") != -1 {
+		t.Errorf("未开启时应为 -1: %d", res.AttemptsRemaining)
+	}
+}
+
+func TestAttemptsConcurrentGuesses(t *testing.T) {
+	_, bad := attemptCodes(t)
+	const limit, n = 5, 50
+	v := &Validator{Window: 1, MaxAttempts: limit}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	checked := 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.ValidateAt("alice", attemptSecret, bad, Options{}, attemptTime)
+			if !errors.Is(err, ErrTooManyAttempts) {
+				mu.Lock()
+				checked++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if checked != limit {
+		t.Errorf("并发猜测时实际验证了 %d 次，最多应为 %d 次", checked, limit)
+	}
+}
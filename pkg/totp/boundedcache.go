@@ -0,0 +1,89 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 09:02:47
+package totp
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiryEntry boundedCache 的条目，index 为其在 expiryHeap 中的位置
+type expiryEntry[K comparable, V any] struct {
+	key    K
+	value  V
+	expiry time.Time
+	index  int
+}
+
+// expiryHeap 按过期时间排序的小顶堆，堆顶为最早过期的条目
+type expiryHeap[K comparable, V any] []*expiryEntry[K, V]
+
+func (h expiryHeap[K, V]) Len() int           { return len(h) }
+func (h expiryHeap[K, V]) Less(i, j int) bool { return h[i].expiry.Before(h[j].expiry) }
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *expiryHeap[K, V]) Push(x any) {
+	e := x.(*expiryEntry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *expiryHeap[K, V]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// boundedCache 条目数有上限、按过期时间淘汰的映射，MemoryReplayCache 与 MemoryResultCache 共用
+// 写入时只从堆顶清理已过期的条目，每个条目只被清理一次，单次写入的均摊开销为 O(log n)
+// 本身不加锁，由调用方串行访问
+type boundedCache[K comparable, V any] struct {
+	entries    map[K]*expiryEntry[K, V]
+	expiries   expiryHeap[K, V]
+	maxEntries int
+}
+
+// newBoundedCache 创建最多保存 maxEntries 个条目的 boundedCache
+func newBoundedCache[K comparable, V any](maxEntries int) boundedCache[K, V] {
+	return boundedCache[K, V]{entries: make(map[K]*expiryEntry[K, V]), maxEntries: maxEntries}
+}
+
+// get 返回在 now 时未过期的条目
+func (c *boundedCache[K, V]) get(k K, now time.Time) (V, bool) {
+	e, ok := c.entries[k]
+	if !ok || !now.Before(e.expiry) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// expire 从堆顶依次删除在 now 时已过期的条目
+func (c *boundedCache[K, V]) expire(now time.Time) {
+	for len(c.expiries) > 0 && !now.Before(c.expiries[0].expiry) {
+		e := heap.Pop(&c.expiries).(*expiryEntry[K, V])
+		delete(c.entries, e.key)
+	}
+}
+
+// put 写入或更新条目；条目已满时先淘汰最早过期的条目
+func (c *boundedCache[K, V]) put(k K, v V, expiry time.Time) {
+	if e, ok := c.entries[k]; ok {
+		e.value = v
+		e.expiry = expiry
+		heap.Fix(&c.expiries, e.index)
+		return
+	}
+	if len(c.entries) >= c.maxEntries {
+		oldest := heap.Pop(&c.expiries).(*expiryEntry[K, V])
+		delete(c.entries, oldest.key)
+	}
+	e := &expiryEntry[K, V]{key: k, value: v, expiry: expiry}
+	heap.Push(&c.expiries, e)
+	c.entries[k] = e
+}
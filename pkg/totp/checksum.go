@@ -0,0 +1,51 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 13:47:05
+package totp
+
+// 校验位格式说明：
+// 开启 Options.AddChecksum 后，在 N 位验证码末尾追加 1 位 Luhn 校验位，得到 N+1 位验证码。
+// 例如验证码 "123456" 的校验位为 6，最终显示为 "1234566"。
+// 该格式仅被少数系统用于发现抄写错误，与标准验证器 App 不兼容。
+
+// luhnDigit 计算数字串的 Luhn 校验位
+func luhnDigit(digits string) byte {
+	sum := 0
+	// 从右往左，校验位追加后原最右一位处于需要加倍的位置
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// appendChecksum 在验证码末尾追加 Luhn 校验位
+func appendChecksum(code string) string {
+	return code + string(luhnDigit(code))
+}
+
+// StripChecksum 校验并去掉末尾的 Luhn 校验位
+// 校验位正确时返回去掉校验位的验证码和 true
+func StripChecksum(code string) (string, bool) {
+	if len(code) < 2 {
+		return "", false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return "", false
+		}
+	}
+	payload := code[:len(code)-1]
+	if luhnDigit(payload) != code[len(code)-1] {
+		return "", false
+	}
+	return payload, true
+}
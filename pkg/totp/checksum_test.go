@@ -0,0 +1,72 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 04:52:16
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLuhnDigit(t *testing.T) {
+	tests := map[string]string{
+		"7992739871": "79927398713", // 维基百科中的 Luhn 示例
+		"123456":     "1234566",     // checksum.go 中的格式示例
+		"000000":     "0000000",
+	}
+	for payload, want := range tests {
+		if got := appendChecksum(payload); got != want {
+			t.Errorf("appendChecksum(%s) = %s，期望 %s", payload, got, want)
+		}
+		if got, ok := StripChecksum(want); !ok || got != payload {
+			t.Errorf("StripChecksum(%s) = %s, %v", want, got, ok)
+		}
+	}
+	for _, bad := range []string{"1234567", "79927398710", "12a4566", "1", ""} {
+		if _, ok := StripChecksum(bad); ok {
+			t.Errorf("StripChecksum(%q) 应校验失败", bad)
+		}
+	}
+}
+
+func TestGenerateWithChecksum(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	at := time.Unix(59, 0)
+	code, err := GenerateTOTPWithOptions(secret, at, Options{Digits: 8, AddChecksum: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 9 || code[:8] != "94287082" {
+		t.Fatalf("应为 RFC 向量 94287082 加 1 位校验位: %s", code)
+	}
+	if _, ok := StripChecksum(code); !ok {
+		t.Errorf("生成的校验位不正确: %s", code)
+	}
+}
+
+func TestValidateRejectsBadChecksum(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	opts := Options{AddChecksum: true}
+	code, err := GenerateTOTPWithOptions(secret, time.Now(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidateTOTPWithOptions(secret, code, 1, opts) {
+		t.Errorf("带正确校验位的验证码应通过: %s", code)
+	}
+	bad := code[:6] + string('0'+(code[6]-'0'+1)%10)
+	if ValidateTOTPWithOptions(secret, bad, 1, opts) {
+		t.Errorf("校验位错误的验证码不应通过: %s", bad)
+	}
+	if ValidateTOTPWithOptions(secret, code[:6], 1, opts) {
+		t.Error("开启校验位时缺少校验位的验证码不应通过")
+	}
+
+	v := &Validator{Window: 1}
+	if res, err := v.Validate("alice", secret, bad, opts); err != nil || res.Valid {
+		t.Errorf("Validator 应拒绝校验位错误的验证码: res=%+v err=%v", res, err)
+	}
+	if res, err := v.Validate("alice", secret, code, opts); err != nil || !res.Valid {
+		t.Errorf("Validator 应接受带正确校验位的验证码: res=%+v err=%v", res, err)
+	}
+}
@@ -0,0 +1,73 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 14:02:31
+package totp
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+)
+
+// DeriveIterations 从口令派生密钥时 PBKDF2-HMAC-SHA256 的迭代次数
+// 修改此值会导致同一口令派生出不同的密钥
+const DeriveIterations = 600000
+
+// DefaultDeriveKeyLen 派生密钥的默认长度（字节），即 RFC 6238 推荐的 160 位
+const DefaultDeriveKeyLen = 20
+
+// MinSecretBytes GenerateSecret 允许的最短密钥长度（字节），即 RFC 4226 要求的 128 位
+const MinSecretBytes = 16
+
+// GenerateSecret 从 crypto/rand 读取 bytes 字节生成随机密钥，编码为不带填充的 Base32
+// bytes<=0 时使用 RFC 6238 推荐的 160 位（DefaultDeriveKeyLen）；短于 MinSecretBytes 视为过弱，返回错误
+func GenerateSecret(bytes int) (string, error) {
+	if bytes <= 0 {
+		bytes = DefaultDeriveKeyLen
+	}
+	if bytes < MinSecretBytes {
+		return "", fmt.Errorf("[TOTP] 密钥长度过短: %d 字节，至少需要 %d 字节", bytes, MinSecretBytes)
+	}
+	key := make([]byte, bytes)
+	defer clear(key)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("[TOTP] 生成随机密钥失败: %w", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key), nil
+}
+
+// DeriveSecret 使用 PBKDF2-HMAC-SHA256 从口令和盐派生 TOTP 密钥
+// 派生结果可直接传给 GenerateFromKey，或用 DeriveSecretBase32 编码后保存。
+//
+// 安全提示：
+// - 派生密钥的强度不会超过口令本身的熵，弱口令可被离线暴力破解；
+// - 盐必须随机生成并与账户一起保存，不同账户不要复用同一个盐；
+// - 泄露口令等同于泄露所有由它派生的密钥，且无法单独轮换某个账户。
+// 如无特殊需要，应优先使用随机生成的密钥。
+func DeriveSecret(password, salt []byte, keyLen int) ([]byte, error) {
+	if keyLen <= 0 {
+		keyLen = DefaultDeriveKeyLen
+	}
+	if len(password) == 0 {
+		return nil, fmt.Errorf("[TOTP] 口令不能为空")
+	}
+	if len(salt) == 0 {
+		return nil, fmt.Errorf("[TOTP] 盐不能为空")
+	}
+	key, err := pbkdf2.Key(sha256.New, string(password), salt, DeriveIterations, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("[TOTP] 派生密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// DeriveSecretBase32 派生密钥并编码为不带填充的 Base32 字符串，可直接用作账户密钥
+func DeriveSecretBase32(password, salt []byte, keyLen int) (string, error) {
+	key, err := DeriveSecret(password, salt, keyLen)
+	if err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key), nil
+}
@@ -0,0 +1,60 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 05:03:40
+package totp
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDeriveSecretVectors(t *testing.T) {
+	password := []byte("correct horse battery staple")
+	salt := []byte("go-totp-salt")
+
+	// 期望值由独立的 PBKDF2-HMAC-SHA256 实现（600000 次迭代）计算得出
+	key, err := DeriveSecret(password, salt, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(key); got != "e4dc9e7391e627b7e629b2446f721afd0fae6f71" {
+		t.Errorf("DeriveSecret = %s", got)
+	}
+
+	tests := []struct {
+		keyLen int
+		want   string
+	}{
+		{0, "4TOJ444R4YT3PZRJWJCG64Q27UH2433R"},
+		{32, "4TOJ444R4YT3PZRJWJCG64Q27UH2433ROBUGU763XW7PTV7CRCDA"},
+	}
+	for _, tt := range tests {
+		got, err := DeriveSecretBase32(password, salt, tt.keyLen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("DeriveSecretBase32(keyLen=%d) = %s，期望 %s", tt.keyLen, got, tt.want)
+		}
+	}
+
+	other, err := DeriveSecretBase32(password, []byte("another-salt"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == tests[0].want {
+		t.Error("不同的盐应派生出不同的密钥")
+	}
+}
+
+func TestDeriveSecretRejectsEmptyInput(t *testing.T) {
+	if _, err := DeriveSecret(nil, []byte("salt"), 0); err == nil {
+		t.Error("空口令应返回错误")
+	}
+	if _, err := DeriveSecret([]byte("password"), nil, 0); err == nil {
+		t.Error("空盐应返回错误")
+	}
+	if _, err := DeriveSecretBase32([]byte{}, []byte{}, 0); err == nil {
+		t.Error("DeriveSecretBase32 应同样拒绝空输入")
+	}
+}
@@ -0,0 +1,18 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 15:58:20
+package totp
+
+// EnrollmentWindow 确认录入时前后允许的时间步数
+// 用户需要在 App 中找到账户再输入，允许前后各 1 个时间步
+const EnrollmentWindow = 1
+
+// ConfirmEnrollment 录入新密钥时确认用户已在验证器 App 中完成配置
+// 典型流程：生成密钥并展示给用户 -> 用户输入 App 显示的验证码 -> 通过后再保存账户
+// 密钥无法解码（或开启 opts.RejectWeakKey 时为弱密钥）时返回 false
+func ConfirmEnrollment(secret string, userCode string, opts Options) bool {
+	if CheckSecret(secret, opts) != nil {
+		return false
+	}
+	return ValidateTOTPWithOptions(secret, userCode, EnrollmentWindow, opts)
+}
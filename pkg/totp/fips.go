@@ -0,0 +1,24 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 01:04:26
+package totp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrAlgorithmNotAllowed 开启 Options.FIPSOnly 时使用了策略不允许的算法
+var ErrAlgorithmNotAllowed = errors.New("[TOTP] 当前策略不允许该算法")
+
+// fipsAlgorithms FIPSOnly 下允许的算法
+var fipsAlgorithms = []Algorithm{SHA256, SHA512}
+
+// checkFIPS 在 opts.FIPSOnly 开启时拒绝 fipsAlgorithms 以外的算法，opts 须已补齐默认值
+func checkFIPS(opts Options) error {
+	if !opts.FIPSOnly || slices.Contains(fipsAlgorithms, opts.Algorithm) {
+		return nil
+	}
+	return fmt.Errorf("%w: FIPSOnly 下只允许 SHA256/SHA512，当前为 %s", ErrAlgorithmNotAllowed, opts.Algorithm)
+}
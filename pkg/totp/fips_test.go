@@ -0,0 +1,55 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 08:11:02
+package totp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFIPSOnly(t *testing.T) {
+	at := time.Unix(59, 0)
+	tests := []struct {
+		name    string
+		algo    Algorithm
+		allowed bool
+	}{
+		{"默认算法", "", false},
+		{"SHA1", SHA1, false},
+		{"SHA256", SHA256, true},
+		{"SHA512", SHA512, true},
+	}
+	for _, tt := range tests {
+		secretAlgo := tt.algo
+		if secretAlgo == "" {
+			secretAlgo = SHA1
+		}
+		secret := rfcSecret(secretAlgo)
+		opts := Options{Algorithm: tt.algo, Digits: 8, FIPSOnly: true}
+		code, err := GenerateTOTPWithOptions(secret, at, opts)
+		if tt.allowed {
+			if err != nil {
+				t.Errorf("%s: FIPSOnly 下应允许: %v", tt.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrAlgorithmNotAllowed) || code != "" {
+			t.Errorf("%s: 应返回 ErrAlgorithmNotAllowed，得到 %q, %v", tt.name, code, err)
+		}
+
+		// 验证路径同样拒绝，正确的 SHA1 验证码也不能通过
+		valid, err := GenerateTOTPWithOptions(secret, at, Options{Algorithm: tt.algo, Digits: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ValidateTOTPWithOptions(secret, valid, 1, Options{Algorithm: tt.algo, Digits: 8, FIPSOnly: true}) {
+			t.Errorf("%s: FIPSOnly 下不应通过验证", tt.name)
+		}
+		v := NewValidator(1)
+		if _, err := v.ValidateAt("alice", secret, valid, opts, at); !errors.Is(err, ErrAlgorithmNotAllowed) {
+			t.Errorf("%s: Validator 应返回 ErrAlgorithmNotAllowed: %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 19:20:48
+package totp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CodeFormatter 将动态截取得到的 31 位整数渲染为验证码
+// 生成过程只负责计算 binCode，具体的字符表示（十进制、Steam 字母表、分组、打码等）由格式化器决定
+type CodeFormatter interface {
+	Format(binCode uint32, digits int) string
+}
+
+// DecimalFormatter 标准十进制格式（RFC 4226）：binCode 对 10^digits 取余并补齐前导零
+type DecimalFormatter struct{}
+
+// Format 实现 CodeFormatter
+func (DecimalFormatter) Format(binCode uint32, digits int) string {
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, binCode%mod)
+}
+
+// steamAlphabet Steam 令牌使用的 26 个字符（去掉了易混淆的字符）
+const steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+
+// SteamCodeLength Steam 令牌验证码的长度
+const SteamCodeLength = 5
+
+// SteamFormatter Steam 令牌格式：固定 5 位，从低位起逐位取 binCode 对 26 的余数映射到 Steam 字母表
+// Steam 令牌的位数固定，digits 参数被忽略
+type SteamFormatter struct{}
+
+// Format 实现 CodeFormatter
+func (SteamFormatter) Format(binCode uint32, _ int) string {
+	var b strings.Builder
+	for i := 0; i < SteamCodeLength; i++ {
+		b.WriteByte(steamAlphabet[binCode%uint32(len(steamAlphabet))])
+		binCode /= uint32(len(steamAlphabet))
+	}
+	return b.String()
+}
+
+// GroupedFormatter 在 Inner 的结果中每 Size 位插入一个空格，例如 "123456" -> "123 456"
+// 仅用于展示：分组后的验证码不能直接用于验证
+type GroupedFormatter struct {
+	Inner CodeFormatter // 为 nil 时使用 DecimalFormatter
+	Size  int           // 每组位数，<=0 时不分组
+}
+
+// Format 实现 CodeFormatter
+func (f GroupedFormatter) Format(binCode uint32, digits int) string {
+	code := innerFormatter(f.Inner).Format(binCode, digits)
+	if f.Size <= 0 || len(code) <= f.Size {
+		return code
+	}
+	var b strings.Builder
+	for i, r := range code {
+		if i > 0 && i%f.Size == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MaskedFormatter 只显示 Inner 结果的最后 Visible 位，其余替换为 *，例如 "****56"
+// 仅用于展示（如屏幕共享时），打码后的验证码不能用于验证
+type MaskedFormatter struct {
+	Inner   CodeFormatter // 为 nil 时使用 DecimalFormatter
+	Visible int           // 保留的末尾位数
+}
+
+// Format 实现 CodeFormatter
+func (f MaskedFormatter) Format(binCode uint32, digits int) string {
+	code := innerFormatter(f.Inner).Format(binCode, digits)
+	hidden := len(code) - max(f.Visible, 0)
+	if hidden <= 0 {
+		return code
+	}
+	return strings.Repeat("*", hidden) + code[hidden:]
+}
+
+// innerFormatter 返回被包装的格式化器，未设置时使用十进制格式
+func innerFormatter(f CodeFormatter) CodeFormatter {
+	if f == nil {
+		return DecimalFormatter{}
+	}
+	return f
+}
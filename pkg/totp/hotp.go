@@ -0,0 +1,69 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 10:21:37
+package totp
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxDigits 支持的最大验证码位数
+// 动态截取得到的是 31 位整数（最大约 21 亿），超过 9 位无意义
+const MaxDigits = 9
+
+// checkDigits 检查验证码位数是否在 1~MaxDigits 范围内
+func checkDigits(digits int) error {
+	if digits < 1 || digits > MaxDigits {
+		return fmt.Errorf("[TOTP] 验证码位数必须在 1~%d 之间: %d", MaxDigits, digits)
+	}
+	return nil
+}
+
+// GenerateHOTP 生成指定计数器的一次性密码（HOTP, RFC 4226）
+// 参数说明：
+// - secret: Base32 编码的密钥
+// - counter: 计数器
+// - digits: 验证码位数（1~9）
+// - algo: 哈希算法（SHA1/SHA256/SHA512）
+func GenerateHOTP(secret string, counter uint64, digits int, algo Algorithm) (string, error) {
+	if err := checkDigits(digits); err != nil {
+		return "", err
+	}
+	key, err := decodeBase32Secret(secret)
+	if err != nil {
+		return "", err
+	}
+	defer clear(key)
+	return generateCode(key, counter, digits, algo), nil
+}
+
+// DefaultLookAhead RFC 4226 第 7.2 节建议的向后查找计数器数，容忍用户多按了几次令牌
+const DefaultLookAhead = 10
+
+// ValidateHOTP 在 counter ~ counter+lookAhead 范围内验证 HOTP 验证码（RFC 4226 第 7.2 节）
+// 位数取验证码的长度；匹配时返回 true 和重新同步后的计数器（匹配的计数器 + 1），调用方应保存它，
+// 之后不再接受该计数器及之前的验证码；不匹配或密钥无法解码时返回 false 和原计数器
+// 查找范围不越过 MaxUint64-1，计数器耗尽后不再接受任何验证码
+// lookAhead 越大，被猜中的概率越高，建议不超过 DefaultLookAhead
+func ValidateHOTP(secret, code string, counter uint64, lookAhead int, algo Algorithm) (matched bool, newCounter uint64) {
+	digits := len(code)
+	if checkDigits(digits) != nil || lookAhead < 0 {
+		return false, counter
+	}
+	key, err := decodeBase32Secret(secret)
+	if err != nil {
+		return false, counter
+	}
+	defer clear(key)
+	for i := 0; i <= lookAhead; i++ {
+		c := counter + uint64(i)
+		if c < counter || c == math.MaxUint64 {
+			break // 计数器溢出；MaxUint64 之后没有可用的计数器，接受它会使返回的计数器回绕为 0
+		}
+		if CodesEqual(generateCode(key, c, digits, algo), code) {
+			return true, c + 1
+		}
+	}
+	return false, counter
+}
@@ -0,0 +1,75 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 09:21:33
+package totp
+
+import (
+	"math"
+	"testing"
+)
+
+// rfc4226Codes RFC 4226 附录 D 中计数器 0~9 的验证码（SHA1，6 位）
+var rfc4226Codes = []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+func TestGenerateHOTPVectors(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	for c, want := range rfc4226Codes {
+		if got, err := GenerateHOTP(secret, uint64(c), 6, SHA1); err != nil || got != want {
+			t.Errorf("计数器 %d: %s %v，期望 %s", c, got, err, want)
+		}
+	}
+}
+
+func TestValidateHOTPLookAhead(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	tests := []struct {
+		name        string
+		code        string
+		counter     uint64
+		lookAhead   int
+		matched     bool
+		nextCounter uint64
+	}{
+		{"当前计数器", rfc4226Codes[0], 0, DefaultLookAhead, true, 1},
+		{"向后 k 个计数器", rfc4226Codes[3], 0, DefaultLookAhead, true, 4},
+		{"恰好在查找范围末尾", rfc4226Codes[9], 2, 7, true, 10},
+		{"超出查找范围", rfc4226Codes[9], 2, 6, false, 2},
+		{"已使用过的计数器", rfc4226Codes[2], 5, DefaultLookAhead, false, 5},
+		{"lookAhead 为 0 时接受当前计数器", rfc4226Codes[5], 5, 0, true, 6},
+		{"lookAhead 为 0 时不向后查找", rfc4226Codes[6], 5, 0, false, 5},
+		{"lookAhead 为负数", rfc4226Codes[5], 5, -1, false, 5},
+		{"错误的验证码", "000000", 0, DefaultLookAhead, false, 0},
+		{"无效的位数", "1234567890", 0, DefaultLookAhead, false, 0},
+	}
+	for _, tt := range tests {
+		matched, next := ValidateHOTP(secret, tt.code, tt.counter, tt.lookAhead, SHA1)
+		if matched != tt.matched || next != tt.nextCounter {
+			t.Errorf("%s: ValidateHOTP = %v, %d，期望 %v, %d", tt.name, matched, next, tt.matched, tt.nextCounter)
+		}
+	}
+}
+
+func TestValidateHOTPCounterOverflow(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	code := func(c uint64) string {
+		s, err := GenerateHOTP(secret, c, 6, SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	start := uint64(math.MaxUint64 - 2)
+	if matched, next := ValidateHOTP(secret, code(math.MaxUint64-1), start, DefaultLookAhead, SHA1); !matched || next != math.MaxUint64 {
+		t.Errorf("MaxUint64-1 应通过并返回 MaxUint64: %v, %d", matched, next)
+	}
+	// 接受 MaxUint64 会使新的计数器回绕为 0
+	if matched, next := ValidateHOTP(secret, code(math.MaxUint64), start, DefaultLookAhead, SHA1); matched || next != start {
+		t.Errorf("MaxUint64 不应通过: %v, %d", matched, next)
+	}
+	// 查找范围不回绕到 0 之后的计数器
+	for c := range uint64(DefaultLookAhead) {
+		if matched, next := ValidateHOTP(secret, code(c), start, DefaultLookAhead, SHA1); matched || next != start {
+			t.Errorf("回绕后的计数器 %d 不应通过: %v, %d", c, matched, next)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 23:52:26
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// ErrEmptyPIN 带 PIN 的验证码需要非空 PIN
+var ErrEmptyPIN = errors.New("[TOTP] PIN 不能为空")
+
+// 带 PIN 的验证码：简化的 OCRA 风格构造，不是 RFC 6238 TOTP，也不兼容 RFC 6287 OCRA
+// 标准验证器 App 无法生成这种验证码，只用于配套的 PIN 增强令牌。构造如下：
+//
+//	C    = floor(Unix 时间 / Period)，按 CounterBytes 取 8 字节（默认）或 4 字节大端，同 GenerateTOTPWithOptions
+//	P    = SHA-256(PIN 的 UTF-8 字节)，32 字节
+//	H    = HMAC-<Algorithm>(K, C || P)，K 为 Base32 解码后的密钥
+//	code = 按 RFC 4226 对 H 动态截取后取 6 位十进制（Formatter、AddChecksum 同 GenerateTOTPWithOptions）
+//
+// 验证时同样遵守 Options.MaxAgeSteps
+//
+// 测试向量（SHA1，30 秒）：密钥 GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ、PIN "1234"，
+// T = 59 时为 779641，T = 1111111109 时为 769146
+
+// pinHMAC 计算计数器（取低 width 字节）与 PIN 摘要拼接后的 HMAC
+func pinHMAC(key []byte, counter uint64, pinHash [sha256.Size]byte, algo Algorithm, width int) []byte {
+	var buf [8 + sha256.Size]byte
+	binary.BigEndian.PutUint64(buf[:8], counter)
+	copy(buf[8:], pinHash[:])
+	h := hmac.New(getHMACFunc(algo), key)
+	h.Write(buf[8-width:])
+	return h.Sum(nil)
+}
+
+// pinCode 按上述构造计算某个计数器的验证码，opts 须已补齐默认值
+func pinCode(key []byte, counter uint64, pinHash [sha256.Size]byte, opts Options) string {
+	_, binCode := dynamicTruncate(pinHMAC(key, counter, pinHash, opts.Algorithm, opts.CounterBytes))
+	code := opts.Formatter.Format(binCode, opts.Digits)
+	if opts.AddChecksum {
+		code = appendChecksum(code)
+	}
+	return code
+}
+
+// GenerateWithPIN 生成 t 时刻依赖密钥和 PIN 的验证码（构造见上）
+func GenerateWithPIN(secret, pin string, t time.Time, opts Options) (string, error) {
+	if pin == "" {
+		return "", ErrEmptyPIN
+	}
+	opts = opts.withDefaults()
+	key, err := decodeSecretWithOptions(secret, opts)
+	if err != nil {
+		return "", err
+	}
+	defer clear(key)
+	return pinCode(key, uint64(t.Unix()/opts.Period), sha256.Sum256([]byte(pin)), opts), nil
+}
+
+// ValidateWithPIN 在当前时间前后 window 个时间步内验证依赖密钥和 PIN 的验证码，超过 opts.MaxAgeSteps 的不接受
+// PIN 错误与验证码错误无法区分，也不应向用户区分
+func ValidateWithPIN(secret, pin, code string, window int, opts Options) bool {
+	if pin == "" || window < 0 {
+		return false
+	}
+	opts = opts.withDefaults()
+	key, err := decodeSecretWithOptions(secret, opts)
+	if err != nil {
+		return false
+	}
+	defer clear(key)
+	pinHash := sha256.Sum256([]byte(pin))
+	counter := time.Now().Unix() / opts.Period
+	for i := -window; i <= window; i++ {
+		step := counter + int64(i)
+		if step >= 0 && !opts.tooOld(i) && CodesEqual(pinCode(key, uint64(step), pinHash, opts), code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 07:12:48
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGenerateWithPINVectors(t *testing.T) {
+	// 期望值由独立实现按 pin.go 中记录的构造计算得出
+	tests := []struct {
+		unix int64
+		algo Algorithm
+		pin  string
+		opts Options
+		code string
+	}{
+		{59, SHA1, "1234", Options{}, "779641"},
+		{1111111109, SHA1, "1234", Options{}, "769146"},
+		{59, SHA256, "0000", Options{Algorithm: SHA256, Digits: 8}, "22757518"},
+		{1111111109, SHA256, "0000", Options{Algorithm: SHA256, Digits: 8}, "79454946"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateWithPIN(rfcSecret(tt.algo), tt.pin, time.Unix(tt.unix, 0), tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.code {
+			t.Errorf("%s PIN %s @%d = %s，期望 %s", tt.algo, tt.pin, tt.unix, got, tt.code)
+		}
+		// 带 PIN 的验证码与标准 TOTP 不同
+		if std, _ := GenerateTOTPWithOptions(rfcSecret(tt.algo), time.Unix(tt.unix, 0), tt.opts); std == got {
+			t.Errorf("%s @%d: 带 PIN 的验证码不应等于标准 TOTP", tt.algo, tt.unix)
+		}
+	}
+}
+
+func TestValidateWithPIN(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	code, err := GenerateWithPIN(secret, "1234", time.Now(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidateWithPIN(secret, "1234", code, 1, Options{}) {
+		t.Error("正确的 PIN 与验证码应通过")
+	}
+	if ValidateWithPIN(secret, "4321", code, 1, Options{}) {
+		t.Error("错误的 PIN 不应通过")
+	}
+	if ValidateWithPIN(secret, "", code, 1, Options{}) {
+		t.Error("空 PIN 不应通过")
+	}
+	if _, err := GenerateWithPIN(secret, "", time.Now(), Options{}); !errors.Is(err, ErrEmptyPIN) {
+		t.Errorf("空 PIN 应返回 ErrEmptyPIN: %v", err)
+	}
+}
+
+func TestPINCounterBytes(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	at := time.Unix(1111111109, 0)
+	got, err := GenerateWithPIN(secret, "1234", at, Options{CounterBytes: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 按构造独立计算：C 为 4 字节大端
+	pinHash := sha256.Sum256([]byte("1234"))
+	msg := binary.BigEndian.AppendUint32(nil, uint32(at.Unix()/30))
+	h := hmac.New(sha1.New, []byte("12345678901234567890"))
+	h.Write(append(msg, pinHash[:]...))
+	_, binCode := dynamicTruncate(h.Sum(nil))
+	if want := (DecimalFormatter{}).Format(binCode, 6); got != want {
+		t.Errorf("CounterBytes=4 的验证码 = %s，期望 %s", got, want)
+	}
+	if got == "769146" {
+		t.Error("CounterBytes=4 不应与 8 字节计数器的验证码相同")
+	}
+
+	now := time.Now()
+	code, err := GenerateWithPIN(secret, "1234", now, Options{CounterBytes: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidateWithPIN(secret, "1234", code, 1, Options{CounterBytes: 4}) {
+		t.Error("CounterBytes=4 生成的验证码应按相同选项通过")
+	}
+	if ValidateWithPIN(secret, "1234", code, 1, Options{}) {
+		t.Error("默认的 8 字节计数器不应接受 4 字节计数器的验证码")
+	}
+	if ValidateWithPIN(secret, "1234", code, 1, Options{CounterBytes: 2}) {
+		t.Error("无效的 CounterBytes 不应通过")
+	}
+}
+
+func TestPINMaxAgeSteps(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	now := time.Now()
+	tests := []struct {
+		age  int
+		want bool
+	}{
+		{0, true},
+		{1, true},
+		{2, false},
+	}
+	for _, tt := range tests {
+		code, err := GenerateWithPIN(secret, "1234", now.Add(-time.Duration(tt.age)*30*time.Second), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ValidateWithPIN(secret, "1234", code, 2, Options{MaxAgeSteps: 1}); got != tt.want {
+			t.Errorf("过去 %d 个时间步的验证码: MaxAgeSteps=1 时结果 %v，期望 %v", tt.age, got, tt.want)
+		}
+		if !ValidateWithPIN(secret, "1234", code, 2, Options{}) {
+			t.Errorf("未设置 MaxAgeSteps 时窗口内过去 %d 个时间步的验证码应通过", tt.age)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 20:52:16
+package totp
+
+import "encoding/binary"
+
+// Raw 生成验证码过程中的全部中间值，用于与其他实现逐步比对
+type Raw struct {
+	Counter      uint64    // 计数器（TOTP 为 Unix 时间 / 步长）
+	CounterBytes [8]byte   // 计数器的 8 字节大端表示，即 HMAC 的输入（Options.CounterBytes 为 4 时只使用后 4 字节）
+	Algorithm    Algorithm // 实际使用的哈希算法
+	HMAC         []byte    // 完整的 HMAC 摘要
+	Offset       int       // 动态截取的偏移（摘要最后一个字节的低 4 位）
+	BinCode      uint32    // 动态截取得到的 31 位整数
+	Code         string    // 最终验证码（按 opts.Formatter 格式化，不含校验位）
+}
+
+// GenerateRaw 计算 counter 对应的验证码，并返回每一步的中间值
+// TOTP 的 counter 为 t.Unix() / Period；digits 为验证码位数（1~9）
+func GenerateRaw(secret string, counter uint64, digits int, opts Options) (Raw, error) {
+	if err := checkDigits(digits); err != nil {
+		return Raw{}, err
+	}
+	opts.Digits = digits
+	opts = opts.withDefaults()
+	key, err := decodeSecretWithOptions(secret, opts)
+	if err != nil {
+		return Raw{}, err
+	}
+	defer clear(key)
+	raw := Raw{Counter: counter, Algorithm: opts.Algorithm}
+	binary.BigEndian.PutUint64(raw.CounterBytes[:], counter)
+	raw.HMAC = hmacCounter(key, counter, opts.Algorithm, opts.CounterBytes)
+	raw.Offset, raw.BinCode = dynamicTruncate(raw.HMAC)
+	raw.Code = opts.Formatter.Format(raw.BinCode, opts.Digits)
+	return raw, nil
+}
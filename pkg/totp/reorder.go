@@ -0,0 +1,50 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 01:21:45
+package totp
+
+import (
+	"fmt"
+	"slices"
+)
+
+// stepWindow 某账户最近接受的时间步：high 为最大值，seen 为 [high-N, high] 内已接受的时间步
+type stepWindow struct {
+	high int64
+	seen []int64
+}
+
+// acceptStep 在 AllowReorderWindow 开启时检查并记录 accountKey 接受的时间步
+// 早于 high-N 的时间步视为过期，窗口内已接受过的时间步视为重放，两者都返回包装了 ErrCodeReplayed 的错误
+func (v *Validator) acceptStep(accountKey string, step int64) error {
+	n := int64(v.AllowReorderWindow)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.reorder == nil {
+		v.reorder = make(map[string]*stepWindow)
+	}
+	w, ok := v.reorder[accountKey]
+	if !ok {
+		v.reorder[accountKey] = &stepWindow{high: step, seen: []int64{step}}
+		return nil
+	}
+	if step < w.high-n {
+		return fmt.Errorf("%w: 时间步 %d 早于最近接受的时间步 %d 超过 %d 步", ErrCodeReplayed, step, w.high, n)
+	}
+	if slices.Contains(w.seen, step) {
+		return ErrCodeReplayed
+	}
+	w.seen = append(w.seen, step)
+	if step > w.high {
+		w.high = step
+		w.seen = slices.DeleteFunc(w.seen, func(s int64) bool { return s < w.high-n })
+	}
+	return nil
+}
+
+// ResetReorder 清除 accountKey 的乱序窗口（例如重置密钥后）
+func (v *Validator) ResetReorder(accountKey string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.reorder, accountKey)
+}
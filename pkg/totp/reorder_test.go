@@ -0,0 +1,152 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 08:24:09
+package totp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllowReorderWindow(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	const base = int64(50_000_000)
+	now := time.Unix((base+2)*DefaultStep, 0)
+	codeAt := func(step int64) string {
+		code, err := GenerateTOTPWithOptions(secret, time.Unix(step*DefaultStep, 0), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	// 不设置 Replay，只依赖乱序窗口判断重放
+	v := &Validator{Window: 2, AllowReorderWindow: 2}
+	tests := []struct {
+		name string
+		step int64
+		ok   bool
+	}{
+		{"首个验证码", base + 2, true},
+		{"乱序到达的较早验证码", base + 1, true},
+		{"较新的验证码", base + 3, true},
+		{"重放窗口内已接受的验证码", base + 1, false},
+		{"早于窗口的验证码", base, false},
+		{"更新的验证码", base + 4, true},
+		{"重放移出窗口前已接受的验证码", base + 2, false},
+	}
+	for _, tt := range tests {
+		res, err := v.ValidateAt("alice", secret, codeAt(tt.step), Options{}, now)
+		if tt.ok {
+			if err != nil || !res.Valid || res.Step != tt.step {
+				t.Errorf("%s（时间步 %d）应通过: res=%+v err=%v", tt.name, tt.step, res, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrCodeReplayed) || res.Valid {
+			t.Errorf("%s（时间步 %d）应返回 ErrCodeReplayed: res=%+v err=%v", tt.name, tt.step, res, err)
+		}
+	}
+
+	// 各账户的窗口互不影响，ResetReorder 后重新开始
+	if res, err := v.ValidateAt("bob", secret, codeAt(base), Options{}, now); err != nil || !res.Valid {
+		t.Errorf("其他账户不受影响: res=%+v err=%v", res, err)
+	}
+	v.ResetReorder("alice")
+	if res, err := v.ValidateAt("alice", secret, codeAt(base+1), Options{}, now); err != nil || !res.Valid {
+		t.Errorf("ResetReorder 后应重新接受: res=%+v err=%v", res, err)
+	}
+}
+
+func TestAllowReorderWindowWithReplay(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	const base = int64(50_000_000)
+	now := time.Unix((base+1)*DefaultStep, 0)
+	v := NewValidator(1)
+	v.AllowReorderWindow = 1
+	for _, step := range []int64{base + 1, base, base + 2} {
+		code, err := GenerateTOTPWithOptions(secret, time.Unix(step*DefaultStep, 0), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res, err := v.ValidateAt("alice", secret, code, Options{}, now); err != nil || !res.Valid {
+			t.Errorf("交错到达的时间步 %d 应通过: res=%+v err=%v", step, res, err)
+		}
+		if _, err := v.ValidateAt("alice", secret, code, Options{}, now); !errors.Is(err, ErrCodeReplayed) {
+			t.Errorf("时间步 %d 的重放应返回 ErrCodeReplayed: %v", step, err)
+		}
+	}
+}
+
+func TestReorderReplayDoesNotConsumeWindow(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	const base = int64(50_000_000)
+	now := time.Unix((base+2)*DefaultStep, 0)
+	codeAt := func(step int64) string {
+		code, err := GenerateTOTPWithOptions(secret, time.Unix(step*DefaultStep, 0), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	// 两个实例共享防重放缓存，乱序窗口各自保存在进程内
+	shared := NewMemoryReplayCache()
+	a := &Validator{Window: 3, Replay: shared, AllowReorderWindow: 1}
+	b := &Validator{Window: 3, Replay: shared, AllowReorderWindow: 1}
+	if res, err := b.ValidateAt("alice", secret, codeAt(base+1), Options{}, now); err != nil || !res.Valid {
+		t.Fatalf("b 首次验证: res=%+v err=%v", res, err)
+	}
+	if res, err := a.ValidateAt("alice", secret, codeAt(base+3), Options{}, now); err != nil || !res.Valid {
+		t.Fatalf("a 验证较新的验证码: res=%+v err=%v", res, err)
+	}
+	// 在 b 上重放 a 已接受的验证码：被防重放缓存拒绝，且不应把 b 的窗口推进到 base+3
+	if _, err := b.ValidateAt("alice", secret, codeAt(base+3), Options{}, now); !errors.Is(err, ErrCodeReplayed) {
+		t.Fatalf("重放应返回 ErrCodeReplayed: %v", err)
+	}
+	if res, err := b.ValidateAt("alice", secret, codeAt(base), Options{}, now); err != nil || !res.Valid {
+		t.Errorf("被拒绝的重放不应挤掉之后合法的乱序验证码: res=%+v err=%v", res, err)
+	}
+}
+
+func TestReplayTriesRemainingSteps(t *testing.T) {
+	// 1 位验证码在窗口内经常重复，用于构造同一验证码对应多个时间步的情形
+	secret := rfcSecret(SHA1)
+	opts := Options{Digits: 1}
+	const window = 5
+	now := time.Unix(50_000_000*DefaultStep, 0)
+	codes, err := WindowCodes(secret, now, opts, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var code string
+	var offsets []int
+	for i, c := range codes {
+		var same []int
+		for j, d := range codes {
+			if d == c {
+				same = append(same, j-window)
+			}
+		}
+		if len(same) > 1 {
+			code, offsets = codes[i], same
+			break
+		}
+	}
+	if offsets == nil {
+		t.Fatal("窗口内没有重复的验证码")
+	}
+
+	for _, v := range []*Validator{NewValidator(window), {Window: window, AllowReorderWindow: 2 * window}} {
+		for _, want := range offsets {
+			res, err := v.ValidateAt("alice", secret, code, opts, now)
+			if err != nil || !res.Valid || res.Offset != want {
+				t.Errorf("验证码 %s 应按偏移 %d 通过: res=%+v err=%v", code, want, res, err)
+			}
+		}
+		if _, err := v.ValidateAt("alice", secret, code, opts, now); !errors.Is(err, ErrCodeReplayed) {
+			t.Errorf("所有匹配的时间步都已使用后应返回 ErrCodeReplayed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 04:36:20
+package totp
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultReplayCacheSize NewMemoryReplayCache 最多保存的条目数
+const DefaultReplayCacheSize = 100000
+
+// replayKey 防重放缓存的键
+type replayKey struct {
+	account string
+	step    int64
+}
+
+// MemoryReplayCache 进程内防重放缓存，条目数有上限（见 boundedCache）
+type MemoryReplayCache struct {
+	mu    sync.Mutex
+	cache boundedCache[replayKey, struct{}]
+}
+
+// NewMemoryReplayCache 创建最多保存 DefaultReplayCacheSize 个条目的进程内防重放缓存
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return NewBoundedReplayCache(DefaultReplayCacheSize)
+}
+
+// NewBoundedReplayCache 创建最多保存 maxEntries 个条目的进程内防重放缓存，maxEntries<=0 时使用 DefaultReplayCacheSize
+// 条目已满时淘汰最早过期的条目，被淘汰的 (账户, 时间步) 在原有效期内可以再次通过，
+// 因此容量应大于 TTL（约 2*Window+2 个步长）内可能被接受的验证码数
+func NewBoundedReplayCache(maxEntries int) *MemoryReplayCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultReplayCacheSize
+	}
+	return &MemoryReplayCache{cache: newBoundedCache[replayKey, struct{}](maxEntries)}
+}
+
+// Seen 实现 ReplayCache
+func (c *MemoryReplayCache) Seen(accountKey string, step int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.cache.get(replayKey{accountKey, step}, time.Now())
+	return ok
+}
+
+// Mark 实现 ReplayCache
+func (c *MemoryReplayCache) Mark(accountKey string, step int64, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.cache.expire(now)
+	c.cache.put(replayKey{accountKey, step}, struct{}{}, now.Add(ttl))
+}
+
+// MarkIfUnseen 实现 AtomicReplayCache
+func (c *MemoryReplayCache) MarkIfUnseen(accountKey string, step int64, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.cache.expire(now)
+	k := replayKey{accountKey, step}
+	if _, ok := c.cache.get(k, now); ok {
+		return false
+	}
+	c.cache.put(k, struct{}{}, now.Add(ttl))
+	return true
+}
+
+// Len 返回当前缓存的条目数（含尚未清理的过期条目）
+func (c *MemoryReplayCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.cache.entries)
+}
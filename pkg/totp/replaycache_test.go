@@ -0,0 +1,78 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 04:44:51
+package totp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplayCacheExpiry(t *testing.T) {
+	c := NewMemoryReplayCache()
+	if !c.MarkIfUnseen("alice", 100, 20*time.Millisecond) {
+		t.Fatal("首次记录应成功")
+	}
+	if c.MarkIfUnseen("alice", 100, 20*time.Millisecond) || !c.Seen("alice", 100) {
+		t.Fatal("有效期内应视为已使用")
+	}
+	if c.Seen("bob", 100) || c.Seen("alice", 101) {
+		t.Fatal("不同账户或时间步互不影响")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if c.Seen("alice", 100) {
+		t.Error("过期后不应再视为已使用")
+	}
+	c.Mark("bob", 1, time.Minute)
+	if n := c.Len(); n != 1 {
+		t.Errorf("写入时应清理过期条目，Len = %d", n)
+	}
+	if !c.MarkIfUnseen("alice", 100, time.Minute) {
+		t.Error("过期后应可再次记录")
+	}
+}
+
+func TestReplayCacheBounded(t *testing.T) {
+	c := NewBoundedReplayCache(3)
+	for step := int64(0); step < 5; step++ {
+		c.Mark("alice", step, time.Duration(step+1)*time.Minute)
+	}
+	if n := c.Len(); n != 3 {
+		t.Fatalf("条目数应不超过上限 3，Len = %d", n)
+	}
+	// 淘汰最早过期的条目
+	for step, want := range []bool{false, false, true, true, true} {
+		if got := c.Seen("alice", int64(step)); got != want {
+			t.Errorf("时间步 %d: Seen = %v，期望 %v", step, got, want)
+		}
+	}
+
+	// 重复记录只更新过期时间，不占用新的条目
+	c.Mark("alice", 2, time.Hour)
+	c.Mark("alice", 5, time.Minute)
+	if !c.Seen("alice", 2) || c.Seen("alice", 3) {
+		t.Error("更新过期时间后应淘汰新的最早过期条目")
+	}
+	if n := c.Len(); n != 3 {
+		t.Errorf("Len = %d，期望 3", n)
+	}
+}
+
+func TestValidatorRejectsReplay(t *testing.T) {
+	v := NewValidator(1)
+	code, err := GenerateTOTPWithOptions(rfcSecret(SHA1), time.Now(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := v.Validate("alice", rfcSecret(SHA1), code, Options{}); err != nil || !res.Valid {
+		t.Fatalf("首次验证: res=%+v err=%v", res, err)
+	}
+	if _, err := v.Validate("alice", rfcSecret(SHA1), code, Options{}); !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("重复提交应返回 ErrCodeReplayed: %v", err)
+	}
+	if res, err := v.Validate("bob", rfcSecret(SHA1), code, Options{}); err != nil || !res.Valid {
+		t.Errorf("其他账户不受影响: res=%+v err=%v", res, err)
+	}
+}
@@ -0,0 +1,50 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 12:10:45
+package totp
+
+import "time"
+
+// Result 当前验证码及其相关信息
+type Result struct {
+	Code        string    // 当前验证码
+	Start       time.Time // 有效开始时间（与传入时间同一时区）
+	End         time.Time // 有效结束时间（与传入时间同一时区）
+	SecondsLeft int       // 剩余有效秒数
+	Algorithm   Algorithm // 实际使用的哈希算法
+	Digits      int       // 配置的验证码位数（不含校验位和分组空格，与 Code 的长度不一定相同）
+	Period      int64     // 实际使用的时间步长（秒）
+}
+
+// Now 一次调用获取当前验证码的全部信息，适合 GUI / 嵌入方直接展示
+// opts 中未设置的参数使用默认值（SHA1、30 秒）
+func Now(secret string, opts Options) (Result, error) {
+	return resultAt(secret, time.Now(), opts)
+}
+
+// At 获取 t 时刻的验证码及相关信息，t 在未来时 SecondsLeft 会大于步长
+func At(secret string, t time.Time, opts Options) (Result, error) {
+	return resultAt(secret, t, opts)
+}
+
+// resultAt 计算 t 时刻的 Result，所有字段基于同一时刻
+func resultAt(secret string, t time.Time, opts Options) (Result, error) {
+	opts = opts.withDefaults()
+	code, start, end, err := generateAt(secret, t, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	left := int(end.Sub(t).Seconds())
+	if left < 0 {
+		left = 0
+	}
+	return Result{
+		Code:        code,
+		Start:       start,
+		End:         end,
+		SecondsLeft: left,
+		Algorithm:   opts.Algorithm,
+		Digits:      opts.Digits,
+		Period:      opts.Period,
+	}, nil
+}
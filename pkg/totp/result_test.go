@@ -0,0 +1,81 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 04:25:07
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultFieldsConsistent(t *testing.T) {
+	secret := rfcSecret(SHA256)
+	at := time.Unix(1111111109, 0).In(time.FixedZone("UTC+8", 8*3600))
+	res, err := At(secret, at, Options{Algorithm: SHA256, Digits: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != "68084774" {
+		t.Errorf("Code = %s，期望 RFC 6238 向量 68084774", res.Code)
+	}
+	if res.Algorithm != SHA256 || res.Digits != 8 || res.Period != DefaultStep {
+		t.Errorf("参数回显不正确: %+v", res)
+	}
+	if len(res.Code) != res.Digits {
+		t.Errorf("默认格式下 Code 长度 %d 与 Digits %d 应一致", len(res.Code), res.Digits)
+	}
+	if got := res.End.Sub(res.Start); got != time.Duration(res.Period)*time.Second {
+		t.Errorf("End-Start = %v，期望一个步长", got)
+	}
+	if res.Start.After(at) || !res.End.After(at) {
+		t.Errorf("t=%v 应落在 [%v, %v) 内", at, res.Start, res.End)
+	}
+	if want := int(res.End.Sub(at).Seconds()); res.SecondsLeft != want {
+		t.Errorf("SecondsLeft = %d，期望 %d", res.SecondsLeft, want)
+	}
+	if res.Start.Location() != at.Location() {
+		t.Errorf("Start 的时区应与传入时间一致: %v", res.Start.Location())
+	}
+}
+
+func TestResultDefaults(t *testing.T) {
+	res, err := Now(rfcSecret(SHA1), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Algorithm != SHA1 || res.Digits != 6 || res.Period != DefaultStep {
+		t.Errorf("默认参数不正确: %+v", res)
+	}
+	if res.SecondsLeft < 0 || res.SecondsLeft > int(res.Period) {
+		t.Errorf("SecondsLeft 超出范围: %d", res.SecondsLeft)
+	}
+	if code, _ := GenerateTOTPWithOptions(rfcSecret(SHA1), res.Start, Options{}); code != res.Code {
+		t.Errorf("Code 与起始时间的验证码不一致: %s / %s", res.Code, code)
+	}
+}
+
+func TestResultDigitsConfigured(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	at := time.Unix(59, 0)
+	tests := []struct {
+		name    string
+		opts    Options
+		codeLen int
+		digits  int
+	}{
+		{"默认", Options{}, 6, 6},
+		{"8 位", Options{Digits: 8}, 8, 8},
+		{"校验位", Options{AddChecksum: true}, 7, 6},
+		{"分组", Options{Digits: 8, Formatter: GroupedFormatter{Size: 4}}, 9, 8},
+		{"Steam", Options{Formatter: SteamFormatter{}}, SteamCodeLength, 6},
+	}
+	for _, tt := range tests {
+		res, err := At(secret, at, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(res.Code) != tt.codeLen || res.Digits != tt.digits {
+			t.Errorf("%s: Code=%q Digits=%d，期望长度 %d、Digits %d", tt.name, res.Code, res.Digits, tt.codeLen, tt.digits)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 00:52:08
+package totp
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// DefaultResultTTL Validator.ResultTTL 未设置时结果缓存的有效期
+// 只用于吸收同一请求内的重试，不宜过长
+const DefaultResultTTL = 5 * time.Second
+
+// CachedResult 缓存的验证结果，成功与失败（含 ErrCodeReplayed 等错误）都会缓存
+type CachedResult struct {
+	Result ValidationResult
+	Err    error
+}
+
+// ResultCache 验证结果缓存，键为 resultKey 计算的哈希，不含验证码明文
+// 默认不启用；多实例部署时可替换为 Redis 等共享实现
+type ResultCache interface {
+	// Get 返回未过期的缓存结果
+	Get(key string) (CachedResult, bool)
+	// Put 缓存结果，ttl 后失效
+	Put(key string, r CachedResult, ttl time.Duration)
+}
+
+// resultKey 计算 (accountKey, code, step) 的缓存键
+// step 为验证时刻所在的时间步，跨入下一时间步后同一验证码会重新验证
+func resultKey(accountKey, code string, step int64) string {
+	h := sha256.New()
+	h.Write([]byte(accountKey))
+	h.Write([]byte{0})
+	h.Write([]byte(code))
+	h.Write([]byte{0})
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(step)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DefaultResultCacheSize NewMemoryResultCache 最多保存的条目数
+const DefaultResultCacheSize = 100000
+
+// MemoryResultCache 进程内验证结果缓存，条目数有上限（见 boundedCache）
+type MemoryResultCache struct {
+	mu    sync.Mutex
+	cache boundedCache[string, CachedResult]
+}
+
+// NewMemoryResultCache 创建最多保存 DefaultResultCacheSize 个条目的进程内验证结果缓存
+func NewMemoryResultCache() *MemoryResultCache {
+	return NewBoundedResultCache(DefaultResultCacheSize)
+}
+
+// NewBoundedResultCache 创建最多保存 maxEntries 个条目的进程内验证结果缓存，maxEntries<=0 时使用 DefaultResultCacheSize
+// 条目已满时淘汰最早过期的条目；被淘汰的请求重试时重新验证，仍受防重放保护
+func NewBoundedResultCache(maxEntries int) *MemoryResultCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultResultCacheSize
+	}
+	return &MemoryResultCache{cache: newBoundedCache[string, CachedResult](maxEntries)}
+}
+
+// Get 实现 ResultCache
+func (c *MemoryResultCache) Get(key string) (CachedResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.get(key, time.Now())
+}
+
+// Put 实现 ResultCache
+func (c *MemoryResultCache) Put(key string, r CachedResult, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.cache.expire(now)
+	c.cache.put(key, r, now.Add(ttl))
+}
+
+// Len 返回当前缓存的条目数（含尚未清理的过期条目）
+func (c *MemoryResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.cache.entries)
+}
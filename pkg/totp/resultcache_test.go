@@ -0,0 +1,130 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 08:04:37
+package totp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestResultCacheHit(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	now := time.Unix(1_700_000_000, 0)
+	code, err := GenerateTOTPWithOptions(secret, now, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未开启结果缓存时，重复验证返回 ErrCodeReplayed
+	plain := NewValidator(1)
+	if res, err := plain.ValidateAt("alice", secret, code, Options{}, now); err != nil || !res.Valid {
+		t.Fatalf("首次验证: res=%+v err=%v", res, err)
+	}
+	if _, err := plain.ValidateAt("alice", secret, code, Options{}, now); !errors.Is(err, ErrCodeReplayed) {
+		t.Fatalf("未开启缓存时重复验证应返回 ErrCodeReplayed: %v", err)
+	}
+
+	cache := NewMemoryResultCache()
+	v := NewValidator(1)
+	v.Results = cache
+	first, err := v.ValidateAt("alice", secret, code, Options{}, now)
+	if err != nil || !first.Valid {
+		t.Fatalf("首次验证: res=%+v err=%v", first, err)
+	}
+	// 同一时间步内的重试命中缓存，得到与第一次相同的结果
+	again, err := v.ValidateAt("alice", secret, code, Options{}, now.Add(5*time.Second))
+	if err != nil || again != first {
+		t.Errorf("命中缓存应返回第一次的结果: %+v / %+v err=%v", again, first, err)
+	}
+
+	// 失败结果同样缓存
+	if res, err := v.ValidateAt("alice", secret, "000000", Options{}, now); err != nil || res.Valid {
+		t.Fatalf("错误的验证码: res=%+v err=%v", res, err)
+	}
+	if res, err := v.ValidateAt("alice", secret, "000000", Options{}, now); err != nil || res.Valid {
+		t.Errorf("缓存的失败结果: res=%+v err=%v", res, err)
+	}
+	if n := cache.Len(); n != 2 {
+		t.Errorf("缓存条目数 = %d，期望 2", n)
+	}
+
+	// 其他账户或下一个时间步不命中缓存，按防重放重新判断
+	if res, err := v.ValidateAt("bob", secret, code, Options{}, now); err != nil || !res.Valid {
+		t.Errorf("其他账户不应命中缓存: res=%+v err=%v", res, err)
+	}
+	if _, err := v.ValidateAt("alice", secret, code, Options{}, now.Add(30*time.Second)); !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("下一个时间步不应命中缓存: %v", err)
+	}
+}
+
+func TestResultCacheExpiry(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	now := time.Now()
+	code, err := GenerateTOTPWithOptions(secret, now, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewValidator(1)
+	v.Results = NewMemoryResultCache()
+	v.ResultTTL = 20 * time.Millisecond
+	if res, err := v.ValidateAt("alice", secret, code, Options{}, now); err != nil || !res.Valid {
+		t.Fatalf("首次验证: res=%+v err=%v", res, err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, err := v.ValidateAt("alice", secret, code, Options{}, now); !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("缓存过期后应按防重放拒绝: %v", err)
+	}
+}
+
+func TestResultKey(t *testing.T) {
+	k := resultKey("alice", "123456", 1)
+	for _, other := range []string{resultKey("bob", "123456", 1), resultKey("alice", "123457", 1), resultKey("alice", "123456", 2), resultKey("alice1", "23456", 1)} {
+		if other == k {
+			t.Error("不同的 (accountKey, code, step) 应得到不同的缓存键")
+		}
+	}
+	if resultKey("alice", "123456", 1) != k {
+		t.Error("缓存键应是确定的")
+	}
+}
+
+func TestResultCacheBounded(t *testing.T) {
+	c := NewBoundedResultCache(3)
+	for i := range 5 {
+		c.Put(fmt.Sprint(i), CachedResult{Result: ValidationResult{Step: int64(i)}}, time.Duration(i+1)*time.Minute)
+	}
+	if n := c.Len(); n != 3 {
+		t.Fatalf("条目数应不超过上限 3，Len = %d", n)
+	}
+	// 淘汰最早过期的条目
+	for i, want := range []bool{false, false, true, true, true} {
+		r, ok := c.Get(fmt.Sprint(i))
+		if ok != want || (ok && r.Result.Step != int64(i)) {
+			t.Errorf("条目 %d: Get = %+v, %v，期望存在 %v", i, r, ok, want)
+		}
+	}
+
+	// 更新已有条目不占用新的条目
+	c.Put("2", CachedResult{Result: ValidationResult{Valid: true}}, time.Hour)
+	if r, ok := c.Get("2"); !ok || !r.Result.Valid {
+		t.Errorf("更新后应返回新的结果: %+v, %v", r, ok)
+	}
+	if n := c.Len(); n != 3 {
+		t.Errorf("Len = %d，期望 3", n)
+	}
+
+	// 写入时清理已过期的条目
+	c = NewMemoryResultCache()
+	c.Put("a", CachedResult{}, 20*time.Millisecond)
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := c.Get("a"); ok {
+		t.Error("过期的条目不应返回")
+	}
+	c.Put("b", CachedResult{}, time.Minute)
+	if n := c.Len(); n != 1 {
+		t.Errorf("写入时应清理过期条目，Len = %d", n)
+	}
+}
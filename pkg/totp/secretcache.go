@@ -0,0 +1,80 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 01:38:14
+package totp
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/base32"
+	"sync"
+)
+
+// secretCacheSize 解码密钥缓存的最大条目数，超出时淘汰最久未使用的密钥
+// 足以覆盖 watch 同时显示的全部账户，又不会让密钥频繁变化的常驻服务无限增长
+const secretCacheSize = 64
+
+// secretCacheKey 缓存的键：规范化后的密钥文本与所用的 Base32 编码
+type secretCacheKey struct {
+	text string
+	enc  *base32.Encoding
+}
+
+// secretCacheEntry 缓存条目，key 为缓存持有的解码密钥副本
+type secretCacheEntry struct {
+	id  secretCacheKey
+	key []byte
+}
+
+// lruSecretCache 解码密钥的 LRU 缓存，淘汰或清空时将密钥清零
+// 读取也会调整顺序，因此只使用互斥锁
+type lruSecretCache struct {
+	mu      sync.Mutex
+	order   *list.List // 元素为 *secretCacheEntry，最近使用的在前
+	entries map[secretCacheKey]*list.Element
+}
+
+// secretCache 全局解码密钥缓存
+var secretCache = &lruSecretCache{order: list.New(), entries: make(map[secretCacheKey]*list.Element)}
+
+// get 返回缓存密钥的副本，调用方可以自由修改或清零
+func (c *lruSecretCache) get(id secretCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return bytes.Clone(e.Value.(*secretCacheEntry).key), true
+}
+
+// put 缓存 key 的副本，超出容量时淘汰并清零最久未使用的密钥
+func (c *lruSecretCache) put(id secretCacheKey, key []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[id]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[id] = c.order.PushFront(&secretCacheEntry{id: id, key: bytes.Clone(key)})
+	for c.order.Len() > secretCacheSize {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove 删除条目并将密钥清零，调用方须持有锁
+func (c *lruSecretCache) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*secretCacheEntry)
+	clear(entry.key)
+	delete(c.entries, entry.id)
+}
+
+// reset 清零并删除全部条目
+func (c *lruSecretCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.order.Len() > 0 {
+		c.remove(c.order.Back())
+	}
+}
@@ -0,0 +1,62 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 23:21:40
+package totp
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// SecretStrength 解码密钥并评估其强度，返回密钥的有效位数和警告（不包含密钥内容，可直接记录日志）
+// bits 为解码后的实际字节数 × 8，与输入的大小写、空格和补位无关；无法解码时 bits 为 -1
+// 警告包括：全为零、短于 RFC 4226 要求的 128 位、短于 RFC 6238 推荐的 160 位，以及明显的低熵模式
+// （单一字节重复、短周期重复、连续递增 / 递减的字节）；低熵检查只是启发式的，没有警告不代表密钥是随机生成的
+func SecretStrength(secret string) (bits int, warnings []string) {
+	key, err := decodeBase32Secret(secret)
+	if err != nil {
+		return -1, []string{"密钥无法按 Base32 解码"}
+	}
+	defer clear(key)
+	bits = len(key) * 8
+	if bits == 0 {
+		return 0, []string{"密钥为空"}
+	}
+
+	if isWeakKey(key, 0) {
+		return bits, []string{"密钥全为零"}
+	}
+	switch {
+	case len(key) < DefaultMinKeyBytes:
+		warnings = append(warnings, fmt.Sprintf("密钥只有 %d 位，短于 RFC 4226 要求的 %d 位", bits, DefaultMinKeyBytes*8))
+	case len(key) < DefaultDeriveKeyLen:
+		warnings = append(warnings, fmt.Sprintf("密钥只有 %d 位，短于 RFC 6238 推荐的 %d 位", bits, DefaultDeriveKeyLen*8))
+	}
+	if w := lowEntropyPattern(key); w != "" {
+		warnings = append(warnings, w)
+	}
+	return bits, warnings
+}
+
+// lowEntropyPattern 检查明显的低熵模式，返回描述，没有发现时返回空字符串
+func lowEntropyPattern(key []byte) string {
+	if len(key) < 4 {
+		return ""
+	}
+	// 以 1~4 字节为周期重复（包括单一字节重复，如全 0xFF）
+	for period := 1; period <= 4 && period <= len(key)/2; period++ {
+		if bytes.Equal(key[period:], key[:len(key)-period]) {
+			return fmt.Sprintf("密钥是以 %d 字节为周期的重复内容", period)
+		}
+	}
+	// 连续递增或递减（如 0x00 0x01 0x02 ...）
+	up, down := true, true
+	for i := 1; i < len(key); i++ {
+		up = up && key[i] == key[i-1]+1
+		down = down && key[i] == key[i-1]-1
+	}
+	if up || down {
+		return "密钥是连续递增或递减的字节"
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 07:04:26
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+)
+
+func TestSecretStrength(t *testing.T) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	sequence := make([]byte, 20)
+	for i := range sequence {
+		sequence[i] = byte(i + 1)
+	}
+	random, err := GenerateSecret(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		bits     int
+		warnings int
+		contains string
+	}{
+		{"RFC 6238 种子", rfcSecret(SHA1), 160, 0, ""},
+		{"随机 256 位", random, 256, 0, ""},
+		{"格式不同的同一密钥", strings.ToLower(rfcSecret(SHA1)[:8]) + " " + rfcSecret(SHA1)[8:], 160, 0, ""},
+		{"80 位", "JBSWY3DPEHPK3PXP", 80, 1, "128"},
+		{"128 位", enc.EncodeToString([]byte("0123456789abcdef")), 128, 1, "160"},
+		{"全零", strings.Repeat("A", 32), 160, 1, "全为零"},
+		{"单一字节重复", enc.EncodeToString([]byte(strings.Repeat("\xff", 20))), 160, 1, "1 字节为周期"},
+		{"短周期重复", enc.EncodeToString([]byte(strings.Repeat("abc", 7))), 168, 1, "3 字节为周期"},
+		{"连续递增", enc.EncodeToString(sequence), 160, 1, "递增"},
+		{"无法解码", "not-base32!", -1, 1, "Base32"},
+		{"空密钥", "", 0, 1, "为空"},
+	}
+	for _, tt := range tests {
+		bits, warnings := SecretStrength(tt.secret)
+		if bits != tt.bits || len(warnings) != tt.warnings {
+			t.Errorf("%s: bits=%d warnings=%q，期望 %d 位、%d 条警告", tt.name, bits, warnings, tt.bits, tt.warnings)
+			continue
+		}
+		if tt.contains != "" && !strings.Contains(strings.Join(warnings, "；"), tt.contains) {
+			t.Errorf("%s: 警告 %q 应包含 %q", tt.name, warnings, tt.contains)
+		}
+		for _, w := range warnings {
+			if tt.secret != "" && strings.Contains(w, tt.secret) {
+				t.Errorf("%s: 警告中不应包含密钥: %q", tt.name, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 22:40:52
+package totp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotRFC 参数超出 RFC 6238 / 主流验证器 App 的兼容范围（Options.StrictRFC 或 CheckRFC）
+var ErrNotRFC = errors.New("[TOTP] 参数不符合 RFC 6238")
+
+// rfcAlgorithms RFC 6238 定义的算法，RegisterAlgorithm 注册的算法不在其中
+var rfcAlgorithms = []Algorithm{SHA1, SHA256, SHA512}
+
+// CheckRFC 检查账户参数是否在 RFC 6238 常见范围内：
+// 位数 6 或 8、步长 30 秒、算法 SHA1/SHA256/SHA512、密钥至少 DefaultMinKeyBytes 字节（128 位）且不全为零
+// digits 为 0 时使用 opts.Digits（默认 6 位）；不符合时返回包装了 ErrNotRFC 的错误，说明第一个不符合的参数
+func CheckRFC(secret string, digits int, opts Options) error {
+	if digits != 0 {
+		opts.Digits = digits
+	}
+	opts = opts.withDefaults()
+	key, err := decodeBase32SecretWith(secret, opts.Base32Encoding)
+	if err != nil {
+		return err
+	}
+	defer clear(key)
+	return checkRFCKey(key, opts)
+}
+
+// checkRFCKey 检查位数、步长、算法和已解码的密钥
+func checkRFCKey(key []byte, opts Options) error {
+	if opts.Digits != 6 && opts.Digits != 8 {
+		return fmt.Errorf("%w: 位数必须为 6 或 8，当前为 %d", ErrNotRFC, opts.Digits)
+	}
+	if opts.Period != DefaultStep {
+		return fmt.Errorf("%w: 步长必须为 %d 秒，当前为 %d 秒", ErrNotRFC, DefaultStep, opts.Period)
+	}
+	valid := false
+	for _, a := range rfcAlgorithms {
+		valid = valid || a == opts.Algorithm
+	}
+	if !valid {
+		return fmt.Errorf("%w: 算法必须为 SHA1、SHA256 或 SHA512，当前为 %s", ErrNotRFC, opts.Algorithm)
+	}
+	if opts.CounterBytes != 8 {
+		return fmt.Errorf("%w: 计数器必须为 8 字节，当前为 %d 字节", ErrNotRFC, opts.CounterBytes)
+	}
+	if isWeakKey(key, DefaultMinKeyBytes) {
+		return fmt.Errorf("%w: 密钥必须至少 %d 字节（128 位）且不全为零，当前为 %d 字节", ErrNotRFC, DefaultMinKeyBytes, len(key))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 06:35:12
+package totp
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckRFC(t *testing.T) {
+	if err := RegisterAlgorithm("STRICT-TEST", sha256.New224); err != nil {
+		t.Fatal(err)
+	}
+	strong := rfcSecret(SHA1)
+
+	tests := []struct {
+		name   string
+		secret string
+		digits int
+		opts   Options
+		ok     bool
+	}{
+		{"默认参数", strong, 0, Options{}, true},
+		{"8 位", strong, 8, Options{}, true},
+		{"SHA512", rfcSecret(SHA512), 0, Options{Algorithm: SHA512}, true},
+		{"7 位", strong, 7, Options{}, false},
+		{"4 位", strong, 0, Options{Digits: 4}, false},
+		{"步长 60 秒", strong, 0, Options{Period: 60}, false},
+		{"自定义算法", strong, 0, Options{Algorithm: "STRICT-TEST"}, false},
+		{"4 字节计数器", strong, 0, Options{CounterBytes: 4}, false},
+		{"短密钥", "JBSWY3DPEHPK3PXP", 0, Options{}, false},
+		{"全零密钥", strings.Repeat("A", 32), 0, Options{}, false},
+	}
+	for _, tt := range tests {
+		err := CheckRFC(tt.secret, tt.digits, tt.opts)
+		if tt.ok && err != nil {
+			t.Errorf("%s: 应符合 RFC: %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrNotRFC) {
+			t.Errorf("%s: 应返回 ErrNotRFC: %v", tt.name, err)
+		}
+
+		// 开启 Options.StrictRFC 时生成验证码同样拒绝
+		opts := tt.opts
+		opts.StrictRFC = true
+		if tt.digits != 0 {
+			opts.Digits = tt.digits
+		}
+		_, err = GenerateTOTPWithOptions(tt.secret, time.Unix(59, 0), opts)
+		if tt.ok != (err == nil) {
+			t.Errorf("%s: StrictRFC 生成验证码错误 = %v", tt.name, err)
+		}
+	}
+}
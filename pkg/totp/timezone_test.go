@@ -0,0 +1,62 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 06:04:18
+package totp
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // 测试环境可能没有系统时区数据库
+)
+
+func TestTimezoneIndependent(t *testing.T) {
+	names := []string{
+		"UTC",
+		"Asia/Kathmandu",   // UTC+5:45
+		"Asia/Kolkata",     // UTC+5:30
+		"Australia/Eucla",  // UTC+8:45
+		"America/New_York", // 有夏令时
+		"Europe/London",    // 有夏令时
+		"Australia/Lord_Howe",
+		"Pacific/Chatham",
+	}
+	var locs []*time.Location
+	for _, name := range names {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatalf("加载时区 %s 失败: %v", name, err)
+		}
+		locs = append(locs, loc)
+	}
+
+	ny, _ := time.LoadLocation("America/New_York")
+	instants := []time.Time{
+		time.Now(),
+		time.Unix(1111111109, 0),
+		// 夏令时切换前后（纽约 2026-03-08 02:00 与 2026-11-01 02:00）
+		time.Date(2026, 3, 8, 1, 59, 59, 0, ny),
+		time.Date(2026, 3, 8, 3, 0, 0, 0, ny),
+		time.Date(2026, 11, 1, 1, 30, 0, 0, ny),
+		time.Date(2026, 11, 1, 1, 30, 0, 0, ny).Add(time.Hour), // 重复的 1:30
+	}
+	secret := rfcSecret(SHA1)
+	for _, at := range instants {
+		want, err := At(secret, at.UTC(), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, loc := range locs {
+			got, err := At(secret, at.In(loc), Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Code != want.Code || !got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+				t.Errorf("%v 在 %s 的结果 %s [%v, %v) 与 UTC 的 %s [%v, %v) 不一致",
+					at, loc, got.Code, got.Start, got.End, want.Code, want.Start, want.End)
+			}
+			if !ValidateTOTPWithTime(secret, want.Code, DefaultStep, 0, SHA1, at.In(loc)) {
+				t.Errorf("%v 在 %s 验证失败", at, loc)
+			}
+		}
+	}
+}
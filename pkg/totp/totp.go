@@ -5,15 +5,12 @@ package totp
 
 import (
 	"crypto/hmac"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base32"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"hash"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -38,67 +35,204 @@ const (
 // DefaultStep 默认时间步长（秒），TOTP 通常为 30 秒
 const DefaultStep int64 = 30
 
-// 缓存解码的 Base32 密钥（提高频繁调用性能）
-var (
-	cacheMu    sync.RWMutex
-	cachedKey  []byte
-	cachedText string
-)
+// DefaultMinKeyBytes 启用弱密钥检查时的默认最小密钥长度（字节）
+// RFC 4226 要求共享密钥至少 128 位
+const DefaultMinKeyBytes = 16
+
+// ErrWeakSecret 密钥解码后全为零或长度不足
+var ErrWeakSecret = errors.New("[TOTP] 弱密钥: 解码后全为零或长度不足")
+
+// Options 生成验证码的可选参数，零值即默认行为
+type Options struct {
+	Algorithm Algorithm // 哈希算法，默认 SHA1
+	Period    int64     // 时间步长（秒），默认 DefaultStep
+	Digits    int       // 验证码位数（1~MaxDigits），默认 6；部分银行令牌使用 8 位
+
+	// RejectWeakKey 为 true 时拒绝全零或过短的密钥（返回 ErrWeakSecret）
+	// 默认关闭以保持兼容，建议在录入新账户时开启
+	RejectWeakKey bool
+	// MinKeyBytes 弱密钥检查的最小长度（字节），<=0 时使用 DefaultMinKeyBytes
+	MinKeyBytes int
+
+	// CounterBytes HMAC 输入中计数器的字节数，只能为 8（默认，RFC 4226）或 4
+	// 4 字节（大端，只取计数器低 32 位）仅用于与少数旧系统互通，设置后与标准验证器 App 生成的验证码不再一致
+	CounterBytes int
+
+	// StrictRFC 为 true 时拒绝超出 RFC 6238 常见范围的参数（返回包装了 ErrNotRFC 的错误）：
+	// 位数必须为 6 或 8、步长必须为 30 秒、算法为 SHA1/SHA256/SHA512、密钥至少 128 位
+	// 默认关闭以保持灵活性，需要保证与主流验证器 App 互通时开启
+	StrictRFC bool
+
+	// FIPSOnly 为 true 时只允许 SHA256/SHA512，SHA1（包括未设置算法时的默认值）及遗留、自定义算法
+	// 返回包装了 ErrAlgorithmNotAllowed 的错误，用于禁止使用 SHA1 的合规环境
+	// 注意绝大多数服务提供方只支持 SHA1，开启后会拒绝大量账户；在 FIPS 模式下运行时可设为 fips140.Enabled()
+	FIPSOnly bool
+
+	// AddChecksum 为 true 时在验证码末尾追加 1 位 Luhn 校验位（格式见 checksum.go）
+	// 验证时会先校验并去掉该位，校验位错误直接判定失败
+	AddChecksum bool
+
+	// Base32Encoding 解码密钥使用的 Base32 编码，默认 base32.StdEncoding
+	// 仅用于少数使用非标准字母表的专有令牌；使用自定义字母表的密钥无法导入标准验证器 App
+	// 大小写和空格的规范化仍然生效，因此字母表应为大写
+	Base32Encoding *base32.Encoding
+
+	// MaxAgeSteps 验证时最多接受过去多少个时间步的验证码，<=0 时不限制（只受窗口约束，默认）
+	// 用于“验证码必须在 N 秒内使用”之类的合规要求：窗口内但更早的匹配会被拒绝，
+	// Validator 此时返回包装了 ErrCodeTooOld 的错误，结果中的 AgeSteps 为实际的时间步数
+	MaxAgeSteps int
+
+	// DetectDigitsMismatch 为 true 时，Validator 验证失败且验证码位数与配置不同时，
+	// 再按验证码的实际位数尝试匹配，匹配则返回包装了 ErrDigitsMismatch 的错误并在结果中给出正确位数
+	// 用于提示“位数应设为 8”之类的配置错误；按其他位数匹配的验证码仍判定为失败，也不会被标记为已使用
+	// 默认关闭，避免为猜测者提供额外的匹配机会
+	DetectDigitsMismatch bool
+
+	// Formatter 验证码的字符表示，默认 DecimalFormatter（见 formatter.go）
+	// GroupedFormatter / MaskedFormatter 仅用于展示，使用它们生成的验证码无法通过验证
+	// AddChecksum 的校验位只对十进制验证码有意义
+	Formatter CodeFormatter
+}
+
+// withDefaults 补齐未设置的参数
+func (o Options) withDefaults() Options {
+	if o.Algorithm == "" {
+		o.Algorithm = SHA1
+	}
+	if o.Period <= 0 {
+		o.Period = DefaultStep
+	}
+	if o.Digits == 0 {
+		o.Digits = 6
+	}
+	if o.MinKeyBytes <= 0 {
+		o.MinKeyBytes = DefaultMinKeyBytes
+	}
+	if o.CounterBytes == 0 {
+		o.CounterBytes = 8
+	}
+	if o.Base32Encoding == nil {
+		o.Base32Encoding = base32.StdEncoding
+	}
+	if o.Formatter == nil {
+		o.Formatter = DecimalFormatter{}
+	}
+	return o
+}
 
 // decodeBase32Secret 安全解码 Base32 密钥
 // 功能：
 // - 自动将小写转大写
 // - 去掉空格
 // - 自动补齐 Base32 = 号
-// - 支持缓存，提高性能
+// - 支持缓存，提高性能（LRU，最多 secretCacheSize 个密钥，见 secretcache.go）
 func decodeBase32Secret(secret string) ([]byte, error) {
-	// 转大写并去掉空格
-	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return decodeBase32SecretWith(secret, base32.StdEncoding)
+}
 
-	// 补齐 Base32 长度为 8 的倍数
+// NormalizeSecret 按解码时的规则规范化密钥：转大写、去掉空格、用 = 补齐到 8 的倍数
+// 不做解码，规范化结果相同的两个密钥解码后一定相同，可直接用于比较或去重
+func NormalizeSecret(secret string) string {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
 	if mod := len(secret) % 8; mod != 0 {
 		secret += strings.Repeat("=", 8-mod)
 	}
+	return secret
+}
+
+// FormatSecretForDisplay 将密钥按 4 个字符一组、以空格分隔显示（如 ABCD EFGH IJKL），方便手动抄写
+// 只用于显示：去掉了 = 填充，保存或写入 URI 时应使用原密钥；分组结果经 NormalizeSecret 后与原密钥相同
+func FormatSecretForDisplay(secret string) string {
+	secret = strings.TrimRight(strings.ToUpper(strings.ReplaceAll(secret, " ", "")), "=")
+	var b strings.Builder
+	for i, r := range []rune(secret) {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// decodeBase32SecretWith 使用指定的 Base32 编码解码密钥，规范化和缓存规则同 decodeBase32Secret
+func decodeBase32SecretWith(secret string, enc *base32.Encoding) ([]byte, error) {
+	secret = NormalizeSecret(secret)
 
 	// 读取缓存
-	cacheMu.RLock()
-	if secret == cachedText && cachedKey != nil {
-		key := make([]byte, len(cachedKey))
-		copy(key, cachedKey)
-		cacheMu.RUnlock()
+	id := secretCacheKey{text: secret, enc: enc}
+	if key, ok := secretCache.get(id); ok {
 		return key, nil
 	}
-	cacheMu.RUnlock()
 
 	// Base32 解码
-	key, err := base32.StdEncoding.DecodeString(secret)
+	key, err := enc.DecodeString(secret)
 	if err != nil {
 		// 尝试不带 Padding 的解码
-		key, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+		key, err = enc.WithPadding(base32.NoPadding).DecodeString(secret)
 		if err != nil {
 			return nil, fmt.Errorf("[TOTP] Base32解码失败: %w", err)
 		}
 	}
 
-	// 写入缓存
-	cacheMu.Lock()
-	cachedText = secret
-	cachedKey = key
-	cacheMu.Unlock()
-
+	secretCache.put(id, key)
 	return key, nil
 }
 
-// getHMACFunc 返回对应算法的哈希函数，用于生成 HMAC
-func getHMACFunc(algo Algorithm) func() hash.Hash {
-	switch algo {
-	case SHA256:
-		return sha256.New
-	case SHA512:
-		return sha512.New
-	default: // 默认使用 SHA1
-		return sha1.New
+// ClearSecretCache 将缓存的解码密钥清零并清空缓存，适合在程序退出或长时间空闲前调用
+// 这只是尽力而为：Go 有垃圾回收，字符串不可变，调用方传入的 Base32 密钥字符串、
+// 生成的验证码字符串、栈扩容或 append 扩容时留下的旧副本、已换出到磁盘的内存页都无法由程序可靠清除
+func ClearSecretCache() {
+	secretCache.reset()
+}
+
+// isWeakKey 判断密钥是否全为零或短于 minBytes
+func isWeakKey(key []byte, minBytes int) bool {
+	if len(key) < minBytes {
+		return true
 	}
+	for _, b := range key {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeSecretWithOptions 解码密钥，并按 opts 执行弱密钥检查和 RFC 严格检查
+func decodeSecretWithOptions(secret string, opts Options) ([]byte, error) {
+	// 所有按 Options 计算的路径都经过这里，顺带检查无法在 withDefaults 中报告的参数
+	if opts.CounterBytes != 8 && opts.CounterBytes != 4 {
+		return nil, fmt.Errorf("[TOTP] 计数器字节数只能为 8 或 4: %d", opts.CounterBytes)
+	}
+	if err := checkDigits(opts.Digits); err != nil {
+		return nil, err
+	}
+	if err := checkFIPS(opts); err != nil {
+		return nil, err
+	}
+	key, err := decodeBase32SecretWith(secret, opts.Base32Encoding)
+	if err != nil {
+		return nil, err
+	}
+	if opts.RejectWeakKey && isWeakKey(key, opts.MinKeyBytes) {
+		clear(key)
+		return nil, ErrWeakSecret
+	}
+	if opts.StrictRFC {
+		if err := checkRFCKey(key, opts); err != nil {
+			clear(key)
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// CheckSecret 检查密钥能否解码，并在 opts.RejectWeakKey 开启时检查密钥强度
+// 适合在录入账户时调用
+func CheckSecret(secret string, opts Options) error {
+	key, err := decodeSecretWithOptions(secret, opts.withDefaults())
+	clear(key)
+	return err
 }
 
 // GenerateTOTP 生成当前时间的一次性密码（TOTP）
@@ -106,7 +240,7 @@ func getHMACFunc(algo Algorithm) func() hash.Hash {
 // - secret: Base32 编码的密钥
 // - timestep: 时间步长（秒）
 // - algo: 哈希算法（SHA1/SHA256/SHA512）
-// 返回 6 位字符串验证码
+// 返回 6 位字符串验证码，其他位数使用 GenerateTOTPDigits 或 Options.Digits
 func GenerateTOTP(secret string, timestep int64, algo Algorithm) (string, error) {
 	return GenerateTOTPWithTime(secret, timestep, time.Now(), algo)
 }
@@ -119,27 +253,170 @@ func GenerateTOTPWithTime(secret string, timestep int64, t time.Time, algo Algor
 	if err != nil {
 		return "", err
 	}
+	defer clear(key)
 
-	// 计算时间计数器（Unix 时间 / timestep）
-	counter := t.Unix() / timestep
+	// 计算时间计数器（Unix 时间 / timestep），只取决于时刻本身，与 t 的时区、夏令时无关
+	return generateCode(key, uint64(t.Unix()/timestep), 6, algo), nil
+}
+
+// GenerateTOTPDigits 同 GenerateTOTPWithTime，但生成 digits 位验证码（1~MaxDigits）
+// 动态截取得到的是 31 位整数，超过 9 位无意义，因此返回错误而不是补零
+func GenerateTOTPDigits(secret string, timestep int64, t time.Time, digits int, algo Algorithm) (string, error) {
+	if err := checkDigits(digits); err != nil {
+		return "", err
+	}
+	key, err := decodeBase32Secret(secret)
+	if err != nil {
+		return "", err
+	}
+	defer clear(key)
+	return generateCode(key, uint64(t.Unix()/timestep), digits, algo), nil
+}
+
+// GenerateTOTPWithOptions 按 opts 生成指定时间点的 TOTP
+// 开启 opts.RejectWeakKey 时，弱密钥返回 ErrWeakSecret
+func GenerateTOTPWithOptions(secret string, t time.Time, opts Options) (string, error) {
+	opts = opts.withDefaults()
+	key, err := decodeSecretWithOptions(secret, opts)
+	if err != nil {
+		return "", err
+	}
+	defer clear(key)
+	code := opts.code(key, uint64(t.Unix()/opts.Period))
+	if opts.AddChecksum {
+		code = appendChecksum(code)
+	}
+	return code, nil
+}
+
+// ValidateTOTPWithOptions 按 opts 验证当前时间的验证码
+// window 为前后允许的时间步数；开启 opts.AddChecksum 时校验位也必须一致
+func ValidateTOTPWithOptions(secret, code string, window int, opts Options) bool {
+	codes, err := WindowCodes(secret, time.Now(), opts, window)
+	if err != nil {
+		return false
+	}
+	for i, c := range codes {
+		if c != "" && CodesEqual(c, code) && !opts.tooOld(i-window) {
+			return true
+		}
+	}
+	return false
+}
+
+// tooOld 判断偏移为 offset 的匹配是否超过 MaxAgeSteps
+func (o Options) tooOld(offset int) bool {
+	return o.MaxAgeSteps > 0 && -offset > o.MaxAgeSteps
+}
+
+// WindowCodes 返回 t 前后 window 个时间步（-window ~ +window）的验证码，共 2*window+1 个
+// 中间一个即 t 所在时间步的验证码；密钥只解码一次，适合调用方自行匹配或记录（例如做防重放的布隆过滤）
+// 早于 Unix 纪元的时间步对应空字符串，以保持下标与偏移一一对应
+func WindowCodes(secret string, t time.Time, opts Options, window int) ([]string, error) {
+	if window < 0 {
+		return nil, fmt.Errorf("[TOTP] 无效的窗口大小: %d", window)
+	}
+	opts = opts.withDefaults()
+	key, err := decodeSecretWithOptions(secret, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	counter := t.Unix() / opts.Period
+	codes := make([]string, 0, 2*window+1)
+	for i := -window; i <= window; i++ {
+		step := counter + int64(i)
+		if step < 0 {
+			codes = append(codes, "")
+			continue
+		}
+		code := opts.code(key, uint64(step))
+		if opts.AddChecksum {
+			code = appendChecksum(code)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// GenerateFromKey 直接使用原始密钥字节生成验证码，跳过 Base32 解码
+// 适用于已从 KMS / HSM 取得原始密钥的调用方，也便于用 RFC 附录中的十六进制种子测试
+// 参数说明：
+// - key: 原始密钥字节
+// - counter: 计数器（TOTP 为 Unix 时间 / 步长，HOTP 为事件计数）
+// - digits: 验证码位数（1~9）
+// - algo: 哈希算法
+func GenerateFromKey(key []byte, counter uint64, digits int, algo Algorithm) (string, error) {
+	if err := checkDigits(digits); err != nil {
+		return "", err
+	}
+	return generateCode(key, counter, digits, algo), nil
+}
+
+// ValidateFromKey 使用原始密钥字节验证验证码
+// window 为计数器前后允许的偏移量（计数器不会小于 0）
+func ValidateFromKey(key []byte, code string, counter uint64, window int, digits int, algo Algorithm) bool {
+	if checkDigits(digits) != nil || window < 0 {
+		return false
+	}
+	for i := -window; i <= window; i++ {
+		if i < 0 && uint64(-i) > counter {
+			continue
+		}
+		if CodesEqual(generateCode(key, counter+uint64(i), digits, algo), code) {
+			return true
+		}
+	}
+	return false
+}
+
+// CodesEqual 以常数时间比较两个验证码，避免逐字节比较的耗时差异泄露验证码内容
+// 长度不同时直接返回 false（长度本身不是秘密）
+func CodesEqual(a, b string) bool {
+	return len(a) == len(b) && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// generateCode 根据密钥和计数器计算 digits 位十进制验证码（HMAC + 动态截取）
+// TOTP 与 HOTP 共用此核心，区别仅在于计数器来源
+func generateCode(key []byte, counter uint64, digits int, algo Algorithm) string {
+	return DecimalFormatter{}.Format(truncate(key, counter, algo), digits)
+}
+
+// code 按 opts 的算法和格式化器计算验证码（不含校验位），opts 须已补齐默认值
+func (o Options) code(key []byte, counter uint64) string {
+	return o.codeDigits(key, counter, o.Digits)
+}
+
+// codeDigits 同 code，但使用指定的位数
+func (o Options) codeDigits(key []byte, counter uint64, digits int) string {
+	_, binCode := dynamicTruncate(hmacCounter(key, counter, o.Algorithm, o.CounterBytes))
+	return o.Formatter.Format(binCode, digits)
+}
+
+// truncate 计算 HMAC 并动态截取（RFC 4226 Dynamic Truncation）得到 31 位整数
+func truncate(key []byte, counter uint64, algo Algorithm) uint32 {
+	_, binCode := dynamicTruncate(hmacCounter(key, counter, algo, 8))
+	return binCode
+}
+
+// hmacCounter 计算大端计数器的 HMAC，width 为计数器字节数（8 或 4，4 时只取低 32 位）
+func hmacCounter(key []byte, counter uint64, algo Algorithm, width int) []byte {
 	var buf [8]byte
-	binary.BigEndian.PutUint64(buf[:], uint64(counter)) //  // 转成 8 字节
+	binary.BigEndian.PutUint64(buf[:], counter) // 转成 8 字节
 
-	// 生成 HMAC
 	h := hmac.New(getHMACFunc(algo), key)
-	h.Write(buf[:])
-	sum := h.Sum(nil)
+	h.Write(buf[8-width:])
+	return h.Sum(nil)
+}
 
-	// 动态截取（Dynamic Truncation）
-	offset := sum[len(sum)-1] & 0x0F
-	binCode := (uint32(sum[offset])&0x7F)<<24 |
+// dynamicTruncate 动态截取：取摘要最后一个字节的低 4 位为偏移，读出 4 字节并去掉最高位
+func dynamicTruncate(sum []byte) (offset int, binCode uint32) {
+	offset = int(sum[len(sum)-1] & 0x0F)
+	binCode = (uint32(sum[offset])&0x7F)<<24 |
 		(uint32(sum[offset+1])&0xFF)<<16 |
 		(uint32(sum[offset+2])&0xFF)<<8 |
 		(uint32(sum[offset+3]) & 0xFF)
-
-	// 对 10^6 取余，得到 6 位验证码
-	code := binCode % 1000000
-	return fmt.Sprintf("%06d", code), nil
+	return offset, binCode
 }
 
 // ValidateTOTP 验证用户输入的验证码是否正确
@@ -149,14 +426,102 @@ func GenerateTOTPWithTime(secret string, timestep int64, t time.Time, algo Algor
 // - timestep: 时间步长
 // - window: 前后允许的时间步数（容忍时间漂移）
 // - algo: 哈希算法
+// 当前时间只读取一次，窗口内的所有验证码都基于同一时刻计算，跨越时间步边界时结果也是确定的
 func ValidateTOTP(secret, code string, timestep int64, window int, algo Algorithm) bool {
+	return ValidateTOTPWithTime(secret, code, timestep, window, algo, time.Now())
+}
+
+// ValidateTOTPWithTime 以 t 所在时间步为中心，按 -window ~ +window 的顺序匹配验证码
+// 服务端验证时 t 应来自可信的时间源（如经 NTP 同步的服务器时钟），
+// 不要使用客户端提交的时间，也不要依赖时钟可能不准的客户端机器
+func ValidateTOTPWithTime(secret, code string, timestep int64, window int, algo Algorithm, t time.Time) bool {
+	matched, _, _ := validateTOTPSkew(secret, code, timestep, window, algo, t)
+	return matched
+}
+
+// ValidateTOTPSkew 同 ValidateTOTP，但同时返回匹配到的时间步偏移 skew（-window ~ +window），
+// 调用方可据此记录最近接受的时间步以防重放，或发现时钟持续偏快/偏慢的客户端
+// 未匹配时 skew 为 0；密钥无法解码、步长或窗口无效时返回错误而不是 false
+func ValidateTOTPSkew(secret, code string, timestep int64, window int, algo Algorithm) (matched bool, skew int, err error) {
+	return validateTOTPSkew(secret, code, timestep, window, algo, time.Now())
+}
+
+// validateTOTPSkew 以 t 所在时间步为中心按 -window ~ +window 的顺序匹配，返回匹配到的偏移
+func validateTOTPSkew(secret, code string, timestep int64, window int, algo Algorithm, t time.Time) (bool, int, error) {
+	if timestep <= 0 {
+		return false, 0, fmt.Errorf("[TOTP] 无效的时间步长: %d", timestep)
+	}
+	if window < 0 {
+		return false, 0, fmt.Errorf("[TOTP] 无效的窗口大小: %d", window)
+	}
+	key, err := decodeBase32Secret(secret)
+	if err != nil {
+		return false, 0, err
+	}
+	defer clear(key)
+	counter := t.Unix() / timestep
 	for i := -window; i <= window; i++ {
-		validCode, err := GenerateTOTPWithTime(secret, timestep, time.Now().Add(time.Duration(i)*time.Duration(timestep)*time.Second), algo)
-		if err == nil && validCode == code {
-			return true
+		step := counter + int64(i)
+		if step >= 0 && CodesEqual(generateCode(key, uint64(step), 6, algo), code) {
+			return true, i, nil
 		}
 	}
-	return false
+	return false, 0, nil
+}
+
+// ValidateTOTPAny 依次用 algos 中的每个算法验证验证码，任一算法匹配即通过，并返回匹配的算法
+// 用于服务提供方更换算法的过渡期；同时接受多个算法会使猜中的概率按算法个数成倍增加，过渡期结束后应改回单一算法
+func ValidateTOTPAny(secret, code string, timestep int64, window int, algos []Algorithm) (Algorithm, bool) {
+	now := time.Now()
+	for _, algo := range algos {
+		if ValidateTOTPWithTime(secret, code, timestep, window, algo, now) {
+			return algo, true
+		}
+	}
+	return "", false
+}
+
+// ValidateTOTPPastOnly 只在过去的 pastSteps 个时间步内验证验证码，不包括当前时间步
+// 用于区分“迟交 / 重放的旧验证码”与正常提交（例如风控分析）
+// 返回匹配到的偏移（-1 ~ -pastSteps）；当前时间步的验证码或不匹配时返回 ok=false
+func ValidateTOTPPastOnly(secret, code string, timestep int64, pastSteps int, algo Algorithm) (offset int, ok bool) {
+	if timestep <= 0 || pastSteps <= 0 {
+		return 0, false
+	}
+	key, err := decodeBase32Secret(secret)
+	if err != nil {
+		return 0, false
+	}
+	defer clear(key)
+	counter := time.Now().Unix() / timestep
+	for i := 1; i <= pastSteps; i++ {
+		step := counter - int64(i)
+		if step < 0 {
+			break
+		}
+		if CodesEqual(generateCode(key, uint64(step), 6, algo), code) {
+			return -i, true
+		}
+	}
+	return 0, false
+}
+
+// ValidateTOTPSeconds 以秒为单位指定时间漂移容忍度验证验证码
+// 容忍度按 ceil(toleranceSeconds/timestep) 换算为前后允许的时间步数，
+// 例如步长 30 秒、容忍 90 秒即前后各 3 个时间步；toleranceSeconds<=0 时只接受当前时间步
+func ValidateTOTPSeconds(secret, code string, timestep int64, toleranceSeconds int, algo Algorithm) bool {
+	if timestep <= 0 {
+		return false
+	}
+	return ValidateTOTP(secret, code, timestep, toleranceWindow(timestep, toleranceSeconds), algo)
+}
+
+// toleranceWindow 将秒数容忍度向上取整换算为时间步数
+func toleranceWindow(timestep int64, toleranceSeconds int) int {
+	if toleranceSeconds <= 0 {
+		return 0
+	}
+	return int((int64(toleranceSeconds) + timestep - 1) / timestep)
 }
 
 // GenerateCurrentTOTP 生成当前时刻的验证码，并返回有效时间范围
@@ -174,3 +539,20 @@ func GenerateCurrentTOTP(secret string, algo Algorithm) (code string, start, end
 	end = start.Add(time.Duration(DefaultStep) * time.Second)
 	return code, start, end, nil
 }
+
+// GenerateCurrentTOTPWithOptions 按 opts（算法、步长）生成当前时刻的验证码，并返回有效时间范围
+func GenerateCurrentTOTPWithOptions(secret string, opts Options) (code string, start, end time.Time, err error) {
+	return generateAt(secret, time.Now(), opts.withDefaults())
+}
+
+// generateAt 生成 t 时刻的验证码及其所在时间步的起止时间
+// 起止时间沿用 t 的时区，便于调用方直接按原时区展示
+func generateAt(secret string, t time.Time, opts Options) (code string, start, end time.Time, err error) {
+	code, err = GenerateTOTPWithOptions(secret, t, opts)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	start = time.Unix((t.Unix()/opts.Period)*opts.Period, 0).In(t.Location())
+	end = start.Add(time.Duration(opts.Period) * time.Second)
+	return code, start, end, nil
+}
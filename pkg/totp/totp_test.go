@@ -0,0 +1,409 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 04:10:33
+package totp
+
+import (
+	"encoding/base32"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret 返回 RFC 6238 附录 B 中各算法使用的 ASCII 种子对应的 Base32 密钥
+func rfcSecret(algo Algorithm) string {
+	seed := map[Algorithm]string{
+		SHA1:   "12345678901234567890",
+		SHA256: "12345678901234567890123456789012",
+		SHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}[algo]
+	return base32.StdEncoding.EncodeToString([]byte(seed))
+}
+
+// rfc6238Vectors RFC 6238 附录 B 的测试向量（8 位，30 秒步长）
+var rfc6238Vectors = []struct {
+	unix int64
+	algo Algorithm
+	code string
+}{
+	{59, SHA1, "94287082"},
+	{59, SHA256, "46119246"},
+	{59, SHA512, "90693936"},
+	{1111111109, SHA1, "07081804"},
+	{1111111109, SHA256, "68084774"},
+	{1111111109, SHA512, "25091201"},
+	{1111111111, SHA1, "14050471"},
+	{1111111111, SHA256, "67062674"},
+	{1111111111, SHA512, "99943326"},
+	{1234567890, SHA1, "89005924"},
+	{1234567890, SHA256, "91819424"},
+	{1234567890, SHA512, "93441116"},
+	{2000000000, SHA1, "69279037"},
+	{2000000000, SHA256, "90698825"},
+	{2000000000, SHA512, "38618901"},
+	{20000000000, SHA1, "65353130"},
+	{20000000000, SHA256, "77737706"},
+	{20000000000, SHA512, "47863826"},
+}
+
+func TestRFC6238Vectors(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		// RFC 的种子都是合格的密钥，开启弱密钥检查后结果不变
+		opts := Options{Algorithm: v.algo, Digits: 8, RejectWeakKey: true}
+		got, err := GenerateTOTPWithOptions(rfcSecret(v.algo), time.Unix(v.unix, 0), opts)
+		if err != nil {
+			t.Fatalf("%s @%d: %v", v.algo, v.unix, err)
+		}
+		if got != v.code {
+			t.Errorf("%s @%d = %s，期望 %s", v.algo, v.unix, got, v.code)
+		}
+	}
+}
+
+func TestRejectWeakKey(t *testing.T) {
+	allZero := strings.Repeat("A", 32) // 20 字节全零
+	short := rfcSecret(SHA1)[:16]      // 10 字节
+	strong := rfcSecret(SHA1)          // 20 字节
+	at := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		opts   Options
+		weak   bool
+	}{
+		{"默认不检查全零密钥", allZero, Options{}, false},
+		{"默认不检查短密钥", short, Options{}, false},
+		{"全零密钥", allZero, Options{RejectWeakKey: true}, true},
+		{"短于默认最小长度", short, Options{RejectWeakKey: true}, true},
+		{"自定义最小长度", short, Options{RejectWeakKey: true, MinKeyBytes: 10}, false},
+		{"短于自定义最小长度", strong, Options{RejectWeakKey: true, MinKeyBytes: 32}, true},
+		{"合格密钥", strong, Options{RejectWeakKey: true}, false},
+	}
+	for _, tt := range tests {
+		err := CheckSecret(tt.secret, tt.opts)
+		if got := errors.Is(err, ErrWeakSecret); got != tt.weak {
+			t.Errorf("%s: CheckSecret 错误 = %v，期望弱密钥 = %v", tt.name, err, tt.weak)
+		}
+		_, err = GenerateTOTPWithOptions(tt.secret, at, tt.opts)
+		if got := errors.Is(err, ErrWeakSecret); got != tt.weak {
+			t.Errorf("%s: 生成验证码错误 = %v，期望弱密钥 = %v", tt.name, err, tt.weak)
+		}
+	}
+	if ConfirmEnrollment(allZero, "328482", Options{RejectWeakKey: true}) {
+		t.Error("开启弱密钥检查时不应确认全零密钥")
+	}
+}
+
+func TestValidateTOTPPastOnly(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	const period = 3600 // 步长取 1 小时，避免测试期间跨越时间步
+	now := time.Now()
+	codeAt := func(offset int) string {
+		at := now.Add(time.Duration(offset) * period * time.Second)
+		code, err := GenerateTOTPWithOptions(secret, at, Options{Period: period})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	if _, ok := ValidateTOTPPastOnly(secret, codeAt(0), period, 2, SHA1); ok {
+		t.Error("当前时间步的验证码不应通过")
+	}
+	for _, offset := range []int{-1, -2} {
+		got, ok := ValidateTOTPPastOnly(secret, codeAt(offset), period, 2, SHA1)
+		if !ok || got != offset {
+			t.Errorf("偏移 %d 的验证码: offset=%d ok=%v", offset, got, ok)
+		}
+	}
+	if _, ok := ValidateTOTPPastOnly(secret, codeAt(-3), period, 2, SHA1); ok {
+		t.Error("超出 pastSteps 的验证码不应通过")
+	}
+	if _, ok := ValidateTOTPPastOnly(secret, codeAt(1), period, 2, SHA1); ok {
+		t.Error("未来时间步的验证码不应通过")
+	}
+	if _, ok := ValidateTOTPPastOnly(secret, codeAt(-1), period, 0, SHA1); ok {
+		t.Error("pastSteps<=0 时不应通过")
+	}
+}
+
+func TestWindowCodes(t *testing.T) {
+	secret := rfcSecret(SHA256)
+	at := time.Unix(1111111109, 0)
+	opts := Options{Algorithm: SHA256, Digits: 8}
+	codes, err := WindowCodes(secret, at, opts, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 5 {
+		t.Fatalf("len = %d，期望 5", len(codes))
+	}
+	if codes[2] != "68084774" {
+		t.Errorf("中间元素 = %s，期望当前时间步的验证码 68084774", codes[2])
+	}
+	for i, code := range codes {
+		want, err := GenerateTOTPWithOptions(secret, at.Add(time.Duration(i-2)*30*time.Second), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != want {
+			t.Errorf("偏移 %d = %s，期望 %s", i-2, code, want)
+		}
+	}
+
+	// 早于 Unix 纪元的时间步为空字符串，下标仍与偏移对应
+	codes, err = WindowCodes(secret, time.Unix(59, 0), opts, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes[0] != "" || codes[1] == "" || codes[2] != "46119246" {
+		t.Errorf("纪元附近的窗口不正确: %q", codes)
+	}
+	if _, err := WindowCodes(secret, at, opts, -1); err == nil {
+		t.Error("负的窗口大小应返回错误")
+	}
+}
+
+func TestCustomBase32Encoding(t *testing.T) {
+	// 将标准字母表循环左移一位
+	rotated := base32.NewEncoding("BCDEFGHIJKLMNOPQRSTUVWXYZ234567A").WithPadding(base32.NoPadding)
+	secret := rotated.EncodeToString([]byte("12345678901234567890"))
+	opts := Options{Digits: 8, Base32Encoding: rotated}
+	at := time.Unix(59, 0)
+
+	got, err := GenerateTOTPWithOptions(secret, at, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "94287082" {
+		t.Errorf("自定义字母表 = %s，期望与 RFC 向量一致 94287082", got)
+	}
+
+	// 大小写与空格的规范化仍然生效
+	spaced := strings.ToLower(secret[:8]) + " " + secret[8:]
+	if got, err := GenerateTOTPWithOptions(spaced, at, opts); err != nil || got != "94287082" {
+		t.Errorf("规范化后应得到相同的验证码: %s, %v", got, err)
+	}
+
+	// 用标准字母表解码同一密钥得到的是另一个密钥
+	if got, err := GenerateTOTPWithOptions(secret, at, Options{Digits: 8}); err == nil && got == "94287082" {
+		t.Error("标准字母表不应得到相同的验证码")
+	}
+}
+
+func TestDigits(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		if v.algo != SHA1 {
+			continue
+		}
+		at := time.Unix(v.unix, 0)
+		got, err := GenerateTOTPDigits(rfcSecret(SHA1), DefaultStep, at, 8, SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v.code {
+			t.Errorf("GenerateTOTPDigits @%d = %s，期望 %s", v.unix, got, v.code)
+		}
+		// 6 位验证码是 8 位验证码的后 6 位
+		if six, _ := GenerateTOTPDigits(rfcSecret(SHA1), DefaultStep, at, 6, SHA1); six != v.code[2:] {
+			t.Errorf("6 位验证码 @%d = %s，期望 %s", v.unix, six, v.code[2:])
+		}
+	}
+
+	secret := rfcSecret(SHA1)
+	code, err := GenerateTOTPWithOptions(secret, time.Now(), Options{Digits: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 8 || !ValidateTOTPWithOptions(secret, code, 1, Options{Digits: 8}) {
+		t.Errorf("8 位验证码应能生成并通过验证: %s", code)
+	}
+	if ValidateTOTPWithOptions(secret, code[2:], 1, Options{Digits: 8}) {
+		t.Error("8 位账户不应接受 6 位验证码")
+	}
+}
+
+func TestInvalidDigits(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	at := time.Unix(59, 0)
+	for _, digits := range []int{-1, 0, MaxDigits + 1} {
+		if _, err := GenerateTOTPDigits(secret, DefaultStep, at, digits, SHA1); err == nil {
+			t.Errorf("GenerateTOTPDigits(digits=%d) 应返回错误", digits)
+		}
+	}
+	for _, digits := range []int{-1, MaxDigits + 1} {
+		if _, err := GenerateTOTPWithOptions(secret, at, Options{Digits: digits}); err == nil {
+			t.Errorf("Options.Digits=%d 应返回错误", digits)
+		}
+		if ValidateTOTPWithOptions(secret, "94287082", 1, Options{Digits: digits}) {
+			t.Errorf("Options.Digits=%d 时不应通过验证", digits)
+		}
+	}
+}
+
+func TestNormalizeSecret(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"JBSWY3DPEHPK3PXP", "jbsw y3dp ehpk 3pxp"},
+		{"JBSWY3DPEHPK3PXP", "JbSwY3dPeHpK3pXp"},
+		{"MZXW6YTBOI", "mzxw6ytboi======"},
+		{"MZXW6YTBOI======", "MZXW 6YTB OI"},
+	}
+	for _, tt := range tests {
+		na, nb := NormalizeSecret(tt.a), NormalizeSecret(tt.b)
+		if na != nb {
+			t.Errorf("NormalizeSecret(%q) = %q，NormalizeSecret(%q) = %q，期望相同", tt.a, na, tt.b, nb)
+		}
+		if len(na)%8 != 0 {
+			t.Errorf("NormalizeSecret(%q) = %q，长度应为 8 的倍数", tt.a, na)
+		}
+		// 规范化结果相同的密钥生成的验证码也相同
+		ca, errA := GenerateTOTPWithOptions(tt.a, time.Unix(59, 0), Options{})
+		cb, errB := GenerateTOTPWithOptions(tt.b, time.Unix(59, 0), Options{})
+		if errA != nil || errB != nil || ca != cb {
+			t.Errorf("%q 与 %q 的验证码不同: %s/%v，%s/%v", tt.a, tt.b, ca, errA, cb, errB)
+		}
+	}
+	if NormalizeSecret("JBSWY3DPEHPK3PXP") == NormalizeSecret("JBSWY3DPEHPK3PXQ") {
+		t.Error("不同的密钥规范化后不应相同")
+	}
+	if got := NormalizeSecret(NormalizeSecret("mzxw 6ytb oi")); got != "MZXW6YTBOI======" {
+		t.Errorf("规范化应幂等: %q", got)
+	}
+}
+
+func TestCounterBytes(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	// 期望值由独立实现以 4 字节大端计数器计算得出
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "00675152"},
+		{1111111109, "14792951"},
+	}
+	for _, tt := range tests {
+		at := time.Unix(tt.unix, 0)
+		got, err := GenerateTOTPWithOptions(secret, at, Options{Digits: 8, CounterBytes: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.code {
+			t.Errorf("4 字节计数器 @%d = %s，期望 %s", tt.unix, got, tt.code)
+		}
+		if got, _ := GenerateTOTPWithOptions(secret, at, Options{Digits: 8, CounterBytes: 8}); got == tt.code {
+			t.Errorf("8 字节计数器 @%d 不应与 4 字节相同", tt.unix)
+		}
+	}
+
+	v := &Validator{Window: 0}
+	at := time.Unix(59, 0)
+	if res, err := v.ValidateAt("alice", secret, "00675152", Options{Digits: 8, CounterBytes: 4}, at); err != nil || !res.Valid {
+		t.Errorf("4 字节计数器的验证码应能通过验证: res=%+v err=%v", res, err)
+	}
+	if res, err := v.ValidateAt("bob", secret, "00675152", Options{Digits: 8}, at); err != nil || res.Valid {
+		t.Errorf("默认的 8 字节计数器不应接受 4 字节计数器的验证码: res=%+v err=%v", res, err)
+	}
+	for _, n := range []int{1, 2, 3, 5, 16} {
+		if _, err := GenerateTOTPWithOptions(secret, time.Now(), Options{CounterBytes: n}); err == nil {
+			t.Errorf("CounterBytes=%d 应返回错误", n)
+		}
+	}
+}
+
+func TestCodesEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"123456", "123456", true},
+		{"", "", true},
+		{"123456", "123457", false},
+		{"123456", "023456", false},
+		{"123456", "12345", false},
+		{"12345", "123456", false},
+		{"123456", "", false},
+		{"73910482", "7391048", false},
+	}
+	for _, tt := range tests {
+		if got := CodesEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("CodesEqual(%q, %q) = %v，期望 %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSecretForDisplay(t *testing.T) {
+	tests := []struct {
+		secret, want string
+	}{
+		{"", ""},
+		{"ABC", "ABC"},
+		{"ABCD", "ABCD"},
+		{"ABCDEFGH", "ABCD EFGH"},
+		{"JBSWY3DPEHPK3PXP", "JBSW Y3DP EHPK 3PXP"},
+		{"JBSWY3DPEHPK3PX", "JBSW Y3DP EHPK 3PX"}, // 长度不是 4 的倍数时最后一组不足 4 个
+		{"ABCDE", "ABCD E"},
+		{"jbsw y3dp ehpk 3pxp", "JBSW Y3DP EHPK 3PXP"},
+		{"MFRGG===", "MFRG G"},
+	}
+	for _, tt := range tests {
+		got := FormatSecretForDisplay(tt.secret)
+		if got != tt.want {
+			t.Errorf("FormatSecretForDisplay(%q) = %q，期望 %q", tt.secret, got, tt.want)
+		}
+		if n := NormalizeSecret(got); n != NormalizeSecret(tt.secret) {
+			t.Errorf("%q 分组后经 NormalizeSecret 得到 %q，与原密钥不一致", tt.secret, n)
+		}
+	}
+}
+
+func TestValidateTOTPCodeLength(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	at := time.Unix(59, 0)
+	code, err := GenerateTOTPWithOptions(secret, at, Options{}) // RFC 6238 向量 94287082 的后 6 位
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "287082" {
+		t.Fatalf("验证码 = %s，期望 287082", code)
+	}
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"正确的验证码", code, true},
+		{"长度相同但末位不同", "287083", false},
+		{"长度相同但首位不同", "387082", false},
+		{"更短", "28708", false},
+		{"更长", "2870820", false},
+		{"8 位验证码", "94287082", false},
+		{"空验证码", "", false},
+	}
+	for _, tt := range tests {
+		if got := ValidateTOTPWithTime(secret, tt.code, 30, 1, SHA1, at); got != tt.want {
+			t.Errorf("%s: ValidateTOTPWithTime(%q) = %v，期望 %v", tt.name, tt.code, got, tt.want)
+		}
+		v := NewValidator(1)
+		if res, err := v.ValidateAt("alice", secret, tt.code, Options{}, at); err != nil || res.Valid != tt.want {
+			t.Errorf("%s: Validator 结果 %+v %v，期望 %v", tt.name, res, err, tt.want)
+		}
+	}
+
+	now, err := GenerateTOTPWithOptions(secret, time.Now(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidateTOTP(secret, now, 30, 1, SHA1) {
+		t.Errorf("ValidateTOTP 应接受当前验证码 %s", now)
+	}
+	wrong := now[:5] + string('0'+(now[5]-'0'+1)%10)
+	for _, bad := range []string{wrong, now[:5], now + "0", ""} {
+		if ValidateTOTP(secret, bad, 30, 1, SHA1) {
+			t.Errorf("ValidateTOTP 不应接受 %q", bad)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-16 13:18:52
+package totp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCodeReplayed 验证码所在时间步已被同一账户使用过
+var ErrCodeReplayed = errors.New("[TOTP] 验证码已被使用")
+
+// ErrDigitsMismatch 验证码按配置的位数不匹配，但按其实际位数匹配（仅在开启 Options.DetectDigitsMismatch 时返回）
+// 通常说明账户的位数设置错误，正确位数见 ValidationResult.DetectedDigits
+var ErrDigitsMismatch = errors.New("[TOTP] 验证码位数与配置不符")
+
+// ErrCodeTooOld 验证码在窗口内匹配，但早于 Options.MaxAgeSteps 允许的时间步
+var ErrCodeTooOld = errors.New("[TOTP] 验证码已超过允许的最长使用时间")
+
+// ErrNoReplayCache Validator 未设置防重放缓存，无法保证一次性使用
+var ErrNoReplayCache = errors.New("[TOTP] 未设置防重放缓存 (Validator.Replay)")
+
+// ReplayCache 防重放缓存，记录每个账户已接受过的时间步
+// 默认使用进程内的 MemoryReplayCache，多实例部署时可替换为 Redis 等共享实现
+type ReplayCache interface {
+	// Seen 判断 (accountKey, step) 是否已被使用
+	Seen(accountKey string, step int64) bool
+	// Mark 记录 (accountKey, step) 已被使用，ttl 后可被清理
+	Mark(accountKey string, step int64, ttl time.Duration)
+}
+
+// AtomicReplayCache 支持原子“检查并记录”的防重放缓存
+// 实现该接口后，并发提交同一验证码时只有一个请求能通过；共享实现可基于 Redis SET NX 等原子操作
+type AtomicReplayCache interface {
+	ReplayCache
+	// MarkIfUnseen 在 (accountKey, step) 未被使用时记录并返回 true，已被使用时返回 false
+	MarkIfUnseen(accountKey string, step int64, ttl time.Duration) bool
+}
+
+// ValidationResult 验证结果
+type ValidationResult struct {
+	Valid  bool  // 是否验证通过
+	Offset int   // 匹配到的时间步偏移（-Window ~ +Window）
+	Step   int64 // 匹配到的时间步（计数器）
+
+	// AgeSteps 匹配到的验证码已过去的时间步数（Offset 为负时为 -Offset，否则为 0），用于审计记录
+	AgeSteps int
+
+	// End 匹配到的时间步的结束时间（不含），可据此告诉用户“验证码还可使用 N 秒”
+	// 匹配到过去的时间步时可能早于验证时间
+	End time.Time
+
+	// DetectedDigits 返回 ErrDigitsMismatch 时为验证码实际匹配的位数，其余情况为 0
+	DetectedDigits int
+
+	// AttemptsRemaining 本次验证后被锁定前还可尝试的次数（见 Validator.MaxAttempts），未开启时为 -1
+	AttemptsRemaining int
+
+	// SecretIndex 匹配到的密钥序号（仅 ValidateRotating）：0 为主密钥，>0 为第 N 个过渡密钥
+	SecretIndex int
+}
+
+// Validator 面向服务端的验证器，支持防重放
+type Validator struct {
+	Window int         // 前后允许的时间步数
+	Replay ReplayCache // 防重放缓存，为 nil 时不做重放检查
+
+	// Results 验证结果缓存，为 nil 时不缓存（默认）
+	// 开启后 ResultTTL 内对同一 (accountKey, 验证码, 当前时间步) 的重复验证直接返回第一次的结果：
+	// 第一次成功时，请求重试得到的仍是成功而不是 ErrCodeReplayed，因此重试必须来自同一请求，
+	// 否则等于在 ResultTTL 内放宽了防重放。ValidateOnce 与 ValidateRotating 不使用缓存
+	Results   ResultCache
+	ResultTTL time.Duration // 结果缓存有效期，<=0 时使用 DefaultResultTTL
+
+	// AllowReorderWindow >0 时按账户记住最近接受的时间步（见 reorder.go）：
+	// 比最近接受的时间步早不超过 N 步、且未被接受过的验证码仍可通过，以容忍网络乱序到达；
+	// 更早的或重复的验证码返回 ErrCodeReplayed。为 0 时只依赖 Replay 按时间步去重（默认）
+	// 状态只保存在本进程内，多实例部署时仍需共享的 Replay
+	AllowReorderWindow int
+
+	// MaxAttempts >0 时按账户统计连续验证失败次数（见 attempts.go），达到后返回 ErrTooManyAttempts，
+	// 验证成功或 ResetAttempts 后清零；为 0 时不限次数（默认）
+	// LockoutDuration 为最近一次失败后多久自动解除锁定，为 0 时只能通过 ResetAttempts 解除
+	MaxAttempts     int
+	LockoutDuration time.Duration
+
+	mu       sync.Mutex                // 保护 rotation、reorder 与 attempts；Replay 不支持 AtomicReplayCache 时也用于串行化检查与记录
+	rotation map[string]*RotationStats // 密钥轮换期间各账户的使用统计
+	reorder  map[string]*stepWindow    // AllowReorderWindow 开启时各账户最近接受的时间步
+	attempts map[string]*attemptState  // MaxAttempts 开启时各账户的连续失败次数
+}
+
+// NewValidator 创建带进程内防重放缓存的验证器
+func NewValidator(window int) *Validator {
+	return &Validator{Window: window, Replay: NewMemoryReplayCache()}
+}
+
+// Validate 验证 accountKey 对应账户的验证码
+// 验证通过后记录所在时间步，同一账户再次提交同一时间步的验证码将返回 ErrCodeReplayed
+func (v *Validator) Validate(accountKey, secret, code string, opts Options) (ValidationResult, error) {
+	return v.ValidateAt(accountKey, secret, code, opts, time.Now())
+}
+
+// ValidateAt 同 Validate，但以 now 代替本机当前时间
+// now 应来自可信的时间源（如经 NTP 同步的服务器时钟），不要使用客户端提交的时间
+func (v *Validator) ValidateAt(accountKey, secret, code string, opts Options, now time.Time) (ValidationResult, error) {
+	return v.limitAttempts(accountKey, now, func() (ValidationResult, error) {
+		return v.validateCached(accountKey, secret, code, opts.withDefaults(), now)
+	})
+}
+
+// validateCached 开启 Results 时先查结果缓存，未命中再验证并写入缓存；opts 须已补齐默认值
+func (v *Validator) validateCached(accountKey, secret, code string, opts Options, now time.Time) (ValidationResult, error) {
+	if v.Results == nil {
+		return v.validateSecret(accountKey, secret, code, opts, now)
+	}
+	cacheKey := resultKey(accountKey, code, now.Unix()/opts.Period)
+	if cached, ok := v.Results.Get(cacheKey); ok {
+		return cached.Result, cached.Err
+	}
+	res, err := v.validateSecret(accountKey, secret, code, opts, now)
+	ttl := v.ResultTTL
+	if ttl <= 0 {
+		ttl = DefaultResultTTL
+	}
+	v.Results.Put(cacheKey, CachedResult{res, err}, ttl)
+	return res, err
+}
+
+// validateSecret 解码密钥并验证，不使用结果缓存；opts 须已补齐默认值
+func (v *Validator) validateSecret(accountKey, secret, code string, opts Options, now time.Time) (ValidationResult, error) {
+	key, err := decodeSecretWithOptions(secret, opts)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	defer clear(key)
+	return v.validateKey(accountKey, key, code, opts, now)
+}
+
+// ValidateOnce 验证验证码并在同一步中将其标记为已使用，用于登录等需要防重放的场景
+// 检查与记录是原子的：并发提交同一验证码时只有一个请求返回 true，其余返回 ErrCodeReplayed
+// Replay 为 nil 时返回 ErrNoReplayCache；Replay 未实现 AtomicReplayCache 时只在本进程内保证原子性
+func (v *Validator) ValidateOnce(accountKey, secret, code string, opts Options) (bool, error) {
+	if v.Replay == nil {
+		return false, ErrNoReplayCache
+	}
+	// 不使用结果缓存，否则并发的相同请求可能都得到缓存中的成功结果
+	now := time.Now()
+	res, err := v.limitAttempts(accountKey, now, func() (ValidationResult, error) {
+		return v.validateSecret(accountKey, secret, code, opts.withDefaults(), now)
+	})
+	return res.Valid, err
+}
+
+// stripChecksumIfNeeded 开启校验位时校验并去掉末尾校验位
+func stripChecksumIfNeeded(code string, opts Options) (string, bool) {
+	if !opts.AddChecksum {
+		return code, true
+	}
+	return StripChecksum(code)
+}
+
+// validateKey 以 now 所在时间步为中心在窗口内匹配验证码，并执行防重放检查
+func (v *Validator) validateKey(accountKey string, key []byte, code string, opts Options, now time.Time) (ValidationResult, error) {
+	code, ok := stripChecksumIfNeeded(code, opts)
+	if !ok {
+		return ValidationResult{}, nil
+	}
+	counter := now.Unix() / opts.Period
+	var tooOld, replayed *ValidationResult
+	var replayErr error
+	for i := -v.Window; i <= v.Window; i++ {
+		step := counter + int64(i)
+		if step < 0 || !CodesEqual(opts.code(key, uint64(step)), code) {
+			continue
+		}
+		res := ValidationResult{Offset: i, Step: step, AgeSteps: max(0, -i), End: time.Unix((step+1)*opts.Period, 0).In(now.Location())}
+		if opts.tooOld(i) {
+			// 继续查找更新的时间步，避免偶然与旧验证码相同时误判
+			if tooOld == nil {
+				tooOld = &res
+			}
+			continue
+		}
+		if err := v.markUsed(accountKey, step, opts); err != nil {
+			// 同上，窗口内其他时间步的验证码可能恰好相同且尚未使用
+			if replayed == nil {
+				replayed, replayErr = &res, err
+			}
+			continue
+		}
+		res.Valid = true
+		return res, nil
+	}
+	if replayed != nil {
+		return *replayed, replayErr
+	}
+	if tooOld != nil {
+		return *tooOld, fmt.Errorf("%w: 验证码已过去 %d 个时间步，最多允许 %d 个", ErrCodeTooOld, tooOld.AgeSteps, opts.MaxAgeSteps)
+	}
+	if opts.DetectDigitsMismatch {
+		return v.detectDigits(key, code, counter, opts)
+	}
+	return ValidationResult{}, nil
+}
+
+// detectDigits 按验证码的实际位数在窗口内重新匹配，匹配时返回 ErrDigitsMismatch
+// 结果的 Valid 始终为 false，也不记录防重放
+func (v *Validator) detectDigits(key []byte, code string, counter int64, opts Options) (ValidationResult, error) {
+	digits := len(code)
+	if digits == opts.Digits || checkDigits(digits) != nil {
+		return ValidationResult{}, nil
+	}
+	for i := -v.Window; i <= v.Window; i++ {
+		step := counter + int64(i)
+		if step >= 0 && CodesEqual(opts.codeDigits(key, uint64(step), digits), code) {
+			res := ValidationResult{Offset: i, Step: step, DetectedDigits: digits}
+			return res, fmt.Errorf("%w: 配置为 %d 位，验证码按 %d 位匹配，位数应设为 %d", ErrDigitsMismatch, opts.Digits, digits, digits)
+		}
+	}
+	return ValidationResult{}, nil
+}
+
+// markUsed 按防重放缓存和乱序窗口检查并记录 accountKey 使用的时间步，已使用或过期时返回包装了 ErrCodeReplayed 的错误
+// 先检查防重放缓存，被判为重放的验证码不会占用乱序窗口
+func (v *Validator) markUsed(accountKey string, step int64, opts Options) error {
+	if v.Replay != nil {
+		// 超出窗口的时间步无法再被接受，缓存只需保留到那之后
+		ttl := time.Duration(int64(2*v.Window+2)*opts.Period) * time.Second
+		if !v.markIfUnseen(accountKey, step, ttl) {
+			return ErrCodeReplayed
+		}
+	}
+	if v.AllowReorderWindow > 0 {
+		return v.acceptStep(accountKey, step)
+	}
+	return nil
+}
+
+// markIfUnseen 原子地检查并记录 (accountKey, step)，已被使用时返回 false
+func (v *Validator) markIfUnseen(accountKey string, step int64, ttl time.Duration) bool {
+	if c, ok := v.Replay.(AtomicReplayCache); ok {
+		return c.MarkIfUnseen(accountKey, step, ttl)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.Replay.Seen(accountKey, step) {
+		return false
+	}
+	v.Replay.Mark(accountKey, step, ttl)
+	return true
+}
+
+// SecretSet 密钥轮换期间的一组密钥
+// Primary 为新密钥，Transitional 为轮换完成前仍被接受的旧密钥
+type SecretSet struct {
+	Primary      string
+	Transitional []string
+}
+
+// RotationStats 密钥轮换期间某账户的使用统计
+type RotationStats struct {
+	PrimaryUses      int       // 主密钥验证成功次数
+	TransitionalUses int       // 过渡密钥验证成功次数
+	Since            time.Time // 开始统计的时间
+	LastTransitional time.Time // 最近一次过渡密钥验证成功的时间
+}
+
+// ValidateRotating 依次使用主密钥和过渡密钥验证，结果中的 SecretIndex 记录实际匹配的密钥
+// 每次成功都会计入 RotationStats，运维可据此判断旧密钥何时可以下线
+func (v *Validator) ValidateRotating(accountKey string, secrets SecretSet, code string, opts Options) (ValidationResult, error) {
+	now := time.Now()
+	return v.limitAttempts(accountKey, now, func() (ValidationResult, error) {
+		return v.validateRotating(accountKey, secrets, code, opts.withDefaults(), now)
+	})
+}
+
+// validateRotating 依次使用各密钥验证；opts 须已补齐默认值
+func (v *Validator) validateRotating(accountKey string, secrets SecretSet, code string, opts Options, now time.Time) (ValidationResult, error) {
+	all := append([]string{secrets.Primary}, secrets.Transitional...)
+	for idx, secret := range all {
+		key, err := decodeSecretWithOptions(secret, opts)
+		if err != nil {
+			return ValidationResult{}, err
+		}
+		res, err := v.validateKey(accountKey, key, code, opts, now)
+		clear(key)
+		if err != nil {
+			return res, err
+		}
+		if res.Valid {
+			res.SecretIndex = idx
+			v.recordRotationUse(accountKey, idx > 0)
+			return res, nil
+		}
+	}
+	return ValidationResult{}, nil
+}
+
+// recordRotationUse 记录一次轮换期间的成功验证
+func (v *Validator) recordRotationUse(accountKey string, transitional bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.rotation == nil {
+		v.rotation = make(map[string]*RotationStats)
+	}
+	stats, ok := v.rotation[accountKey]
+	if !ok {
+		stats = &RotationStats{Since: time.Now()}
+		v.rotation[accountKey] = stats
+	}
+	if transitional {
+		stats.TransitionalUses++
+		stats.LastTransitional = time.Now()
+	} else {
+		stats.PrimaryUses++
+	}
+}
+
+// RotationStats 返回账户在密钥轮换期间的使用统计
+func (v *Validator) RotationStats(accountKey string) RotationStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if stats, ok := v.rotation[accountKey]; ok {
+		return *stats
+	}
+	return RotationStats{}
+}
+
+// CanRetireTransitional 判断旧密钥是否可以下线：
+// 统计时长已超过 quiet，且在 quiet 时间内没有任何过渡密钥验证成功
+func (v *Validator) CanRetireTransitional(accountKey string, quiet time.Duration) bool {
+	stats := v.RotationStats(accountKey)
+	if stats.Since.IsZero() || time.Since(stats.Since) < quiet {
+		return false
+	}
+	return stats.LastTransitional.IsZero() || time.Since(stats.LastTransitional) >= quiet
+}
+
+// ResetRotation 清除账户的轮换统计（旧密钥下线后调用）
+func (v *Validator) ResetRotation(accountKey string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.rotation, accountKey)
+}
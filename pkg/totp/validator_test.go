@@ -0,0 +1,227 @@
+// Package totp
+// Author: wsk20
+// Created on: 2026-10-17 06:26:40
+package totp
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestValidateDeterministicWindow(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	// 时间步 [1111111080, 1111111110) 的最后一纳秒：若验证过程中重新读取时钟，很容易落入下一个时间步
+	now := time.Unix(1111111110, 0).Add(-time.Nanosecond)
+	step := now.Unix() / DefaultStep
+	v := &Validator{Window: 1}
+
+	for offset := -1; offset <= 1; offset++ {
+		at := time.Unix((step+int64(offset))*DefaultStep, 0)
+		code, err := GenerateTOTPWithOptions(secret, at, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			res, err := v.ValidateAt("alice", secret, code, Options{}, now)
+			if err != nil || !res.Valid {
+				t.Fatalf("偏移 %d: res=%+v err=%v", offset, res, err)
+			}
+			if res.Offset != offset || res.Step != step+int64(offset) {
+				t.Errorf("偏移 %d: 匹配结果 Offset=%d Step=%d", offset, res.Offset, res.Step)
+			}
+			if want := at.Add(time.Duration(DefaultStep) * time.Second); !res.End.Equal(want) {
+				t.Errorf("偏移 %d: End=%v，期望 %v", offset, res.End, want)
+			}
+
+			matched, skew, err := validateTOTPSkew(secret, code, DefaultStep, 1, SHA1, now)
+			if err != nil || !matched || skew != offset {
+				t.Errorf("偏移 %d: validateTOTPSkew = %v, %d, %v", offset, matched, skew, err)
+			}
+		}
+	}
+
+	// 窗口之外的下一个时间步在边界前一纳秒不应通过
+	next, _ := GenerateTOTPWithOptions(secret, time.Unix((step+2)*DefaultStep, 0), Options{})
+	if ValidateTOTPWithTime(secret, next, DefaultStep, 1, SHA1, now) {
+		t.Error("窗口之外的验证码不应通过")
+	}
+}
+
+// plainReplayCache 只实现 ReplayCache，不支持原子的检查并记录
+type plainReplayCache struct {
+	inner *MemoryReplayCache
+}
+
+func (c plainReplayCache) Seen(key string, step int64) bool { return c.inner.Seen(key, step) }
+func (c plainReplayCache) Mark(key string, step int64, ttl time.Duration) {
+	c.inner.Mark(key, step, ttl)
+}
+
+func TestValidateOnceConcurrent(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	caches := map[string]ReplayCache{
+		"AtomicReplayCache": NewMemoryReplayCache(),
+		"ReplayCache":       plainReplayCache{NewMemoryReplayCache()},
+	}
+	for name, cache := range caches {
+		v := &Validator{Window: 1, Replay: cache}
+		code, err := GenerateTOTPWithOptions(secret, time.Now(), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		const n = 50
+		var wg sync.WaitGroup
+		var succeeded, replayed atomic.Int32
+		start := make(chan struct{})
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				ok, err := v.ValidateOnce("alice", secret, code, Options{})
+				switch {
+				case ok && err == nil:
+					succeeded.Add(1)
+				case errors.Is(err, ErrCodeReplayed):
+					replayed.Add(1)
+				default:
+					t.Errorf("%s: 意外的结果 ok=%v err=%v", name, ok, err)
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+		if succeeded.Load() != 1 || replayed.Load() != n-1 {
+			t.Errorf("%s: 成功 %d 次、重放 %d 次，期望 1 次与 %d 次", name, succeeded.Load(), replayed.Load(), n-1)
+		}
+	}
+}
+
+func TestValidateOnceNoReplayCache(t *testing.T) {
+	v := &Validator{Window: 1}
+	if _, err := v.ValidateOnce("alice", rfcSecret(SHA1), "123456", Options{}); !errors.Is(err, ErrNoReplayCache) {
+		t.Errorf("未设置 Replay 时应返回 ErrNoReplayCache: %v", err)
+	}
+}
+
+func TestValidationResultEnd(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	opts := Options{Period: 60}
+	now := time.Unix(1_700_000_000, 0) // 所在时间步为 [1699999980, 1700000040)
+	v := &Validator{Window: 2}
+
+	for offset := -2; offset <= 2; offset++ {
+		start := time.Unix((now.Unix()/60+int64(offset))*60, 0)
+		code, err := GenerateTOTPWithOptions(secret, start, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := v.ValidateAt("alice", secret, code, opts, now)
+		if err != nil || !res.Valid || res.Offset != offset {
+			t.Fatalf("偏移 %d: res=%+v err=%v", offset, res, err)
+		}
+		if want := start.Add(time.Minute); !res.End.Equal(want) {
+			t.Errorf("偏移 %d: End=%v，期望匹配时间步的结束时间 %v", offset, res.End, want)
+		}
+		// 剩余有效时间：当前时间步还剩 40 秒，过去的时间步已经结束
+		if left := res.End.Sub(now); left != time.Duration(40+60*offset)*time.Second {
+			t.Errorf("偏移 %d: 剩余 %v", offset, left)
+		}
+		if want := max(0, -offset); res.AgeSteps != want {
+			t.Errorf("偏移 %d: AgeSteps=%d，期望 %d", offset, res.AgeSteps, want)
+		}
+	}
+
+	res, err := v.ValidateAt("alice", secret, "000000", opts, now)
+	if err != nil || res.Valid || !res.End.IsZero() {
+		t.Errorf("未匹配时 End 应为零值: res=%+v err=%v", res, err)
+	}
+}
+
+func TestDetectDigitsMismatch(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	now := time.Unix(59, 0)
+	v := &Validator{Window: 1}
+	detect := Options{DetectDigitsMismatch: true}
+
+	// 账户配置为 6 位，服务方实际发放 8 位
+	res, err := v.ValidateAt("alice", secret, "94287082", detect, now)
+	if !errors.Is(err, ErrDigitsMismatch) || res.Valid || res.DetectedDigits != 8 {
+		t.Errorf("8 位验证码应返回 ErrDigitsMismatch 并给出位数 8: res=%+v err=%v", res, err)
+	}
+	// 反过来：配置为 8 位，实际为 6 位
+	res, err = v.ValidateAt("alice", secret, "287082", Options{Digits: 8, DetectDigitsMismatch: true}, now)
+	if !errors.Is(err, ErrDigitsMismatch) || res.DetectedDigits != 6 {
+		t.Errorf("6 位验证码应返回 ErrDigitsMismatch 并给出位数 6: res=%+v err=%v", res, err)
+	}
+
+	// 默认不开启，只是普通的验证失败
+	if res, err := v.ValidateAt("alice", secret, "94287082", Options{}, now); err != nil || res.Valid || res.DetectedDigits != 0 {
+		t.Errorf("未开启时应只返回验证失败: res=%+v err=%v", res, err)
+	}
+	// 位数不同但按实际位数也不匹配时不误报
+	if res, err := v.ValidateAt("alice", secret, "12345678", detect, now); err != nil || res.Valid {
+		t.Errorf("按实际位数也不匹配时不应返回 ErrDigitsMismatch: res=%+v err=%v", res, err)
+	}
+	// 位数一致时照常验证
+	if res, err := v.ValidateAt("alice", secret, "287082", detect, now); err != nil || !res.Valid {
+		t.Errorf("位数一致时应正常通过: res=%+v err=%v", res, err)
+	}
+}
+
+func TestMaxAgeSteps(t *testing.T) {
+	secret := rfcSecret(SHA1)
+	now := time.Unix(1_700_000_000, 0)
+	codeAt := func(offset int) string {
+		code, err := GenerateTOTPWithOptions(secret, now.Add(time.Duration(offset)*30*time.Second), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		maxAge int
+		offset int
+		ok     bool
+	}{
+		{0, -3, true}, // 默认不限制
+		{2, -2, true}, // 恰好在边界上
+		{2, -3, false},
+		{1, -1, true},
+		{1, -2, false},
+		{1, 0, true},
+		{1, 3, true}, // 只限制过去的时间步
+	}
+	for _, tt := range tests {
+		v := &Validator{Window: 3}
+		opts := Options{MaxAgeSteps: tt.maxAge}
+		res, err := v.ValidateAt("alice", secret, codeAt(tt.offset), opts, now)
+		if tt.ok {
+			if err != nil || !res.Valid || res.AgeSteps != max(0, -tt.offset) {
+				t.Errorf("MaxAgeSteps=%d 偏移 %d 应通过: res=%+v err=%v", tt.maxAge, tt.offset, res, err)
+			}
+		} else {
+			if !errors.Is(err, ErrCodeTooOld) || res.Valid || res.AgeSteps != -tt.offset {
+				t.Errorf("MaxAgeSteps=%d 偏移 %d 应返回 ErrCodeTooOld: res=%+v err=%v", tt.maxAge, tt.offset, res, err)
+			}
+		}
+	}
+
+	// 过旧的验证码不记录防重放，更新的验证码照常可用
+	v := NewValidator(3)
+	opts := Options{MaxAgeSteps: 1}
+	if _, err := v.ValidateAt("alice", secret, codeAt(-2), opts, now); !errors.Is(err, ErrCodeTooOld) {
+		t.Fatalf("应返回 ErrCodeTooOld: %v", err)
+	}
+	if _, err := v.ValidateAt("alice", secret, codeAt(-2), opts, now); !errors.Is(err, ErrCodeTooOld) {
+		t.Errorf("再次提交过旧的验证码应仍为 ErrCodeTooOld 而不是重放: %v", err)
+	}
+	if res, err := v.ValidateAt("alice", secret, codeAt(0), opts, now); err != nil || !res.Valid {
+		t.Errorf("当前验证码应通过: res=%+v err=%v", res, err)
+	}
+}